@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccremer/fronius-exporter/cfg"
+	"github.com/ccremer/fronius-exporter/pkg/fronius"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	log "github.com/sirupsen/logrus"
+)
+
+// backfillDateLayout is the layout of dates without time that are accepted for the backfill range.
+const backfillDateLayout = "2006-01-02"
+
+// runBackfill writes the archive history of the configured range in the OpenMetrics text format with timestamps.
+// The output can be imported with `promtool tsdb create-blocks-from openmetrics`.
+// Dates are interpreted in the timezone of the device, which is detected with an archive request unless it is configured.
+func runBackfill(ctx context.Context, client *fronius.SymoClient, config cfg.BackfillConfig) error {
+	location, err := client.DetectDeviceLocationWithContext(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot detect timezone of the device: %w", err)
+	}
+	start, err := parseBackfillTime(config.Start, location, false)
+	if err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	end := time.Now()
+	if config.End != "" {
+		if end, err = parseBackfillTime(config.End, location, true); err != nil {
+			return fmt.Errorf("invalid end: %w", err)
+		}
+	}
+
+	out := io.Writer(os.Stdout)
+	if config.Output != "-" {
+		file, err := os.Create(config.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	log.WithFields(log.Fields{
+		"start":    start,
+		"end":      end,
+		"channels": client.Options.ArchiveChannels,
+		"output":   config.Output,
+	}).Info("Backfilling archive data.")
+	series, err := client.GetArchiveHistoryWithContext(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	return writeOpenMetrics(out, series)
+}
+
+// parseBackfillTime parses a RFC 3339 timestamp or a date in the given timezone.
+// Dates are interpreted as the start of the day, or as the end of the day if endOfDay is set.
+// If location is nil, only RFC 3339 timestamps are accepted.
+func parseBackfillTime(value string, location *time.Location, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("no date given")
+	}
+	if date, err := time.Parse(backfillDateLayout, value); err == nil {
+		if location == nil {
+			return time.Time{}, fmt.Errorf("the timezone of the device could not be detected, set --symo.timezone or give %q as RFC 3339 timestamp", value)
+		}
+		date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+		if endOfDay {
+			return date.AddDate(0, 0, 1).Add(-time.Second), nil
+		}
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// writeOpenMetrics converts the given archive series to the same metrics that the exporter exposes for the archive
+// and writes them with their timestamps to out.
+func writeOpenMetrics(out io.Writer, series []fronius.ArchiveSeries) error {
+	families := metricFamilies{}
+	for _, s := range series {
+		inverter := strings.TrimPrefix(s.Device, "inverter/")
+		for _, sample := range s.Samples {
+			err := families.add(archiveChannelOpts, archiveChannelLabels, sample, inverter, s.Channel, s.Unit)
+			if err != nil {
+				return err
+			}
+			// The DC string channels are also exposed with the MPPT metrics, like in parseArchiveMetrics.
+			if mppt, ok := strings.CutPrefix(s.Channel, "Current_DC_String_"); ok {
+				err = families.add(siteMPPTCurrentDCOpts, siteMPPTLabels, sample, inverter, mppt)
+			} else if mppt, ok := strings.CutPrefix(s.Channel, "Voltage_DC_String_"); ok {
+				err = families.add(siteMPPTVoltageOpts, siteMPPTLabels, sample, inverter, mppt)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := expfmt.MetricFamilyToOpenMetrics(out, families[name].family); err != nil {
+			return err
+		}
+	}
+	_, err := expfmt.FinalizeOpenMetrics(out)
+	return err
+}
+
+type (
+	// metricFamilies collects timestamped gauge samples keyed by the fully-qualified metric name.
+	metricFamilies map[string]*metricFamily
+	metricFamily   struct {
+		desc   *prometheus.Desc
+		family *dto.MetricFamily
+	}
+)
+
+// add appends the sample with the given label values to the family of the given gauge.
+func (f metricFamilies) add(opts prometheus.GaugeOpts, labels []string, sample fronius.Sample, labelValues ...string) error {
+	name := prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name)
+	mf := f[name]
+	if mf == nil {
+		help := opts.Help
+		mf = &metricFamily{
+			desc: prometheus.NewDesc(name, help, labels, opts.ConstLabels),
+			family: &dto.MetricFamily{
+				Name: &name,
+				Help: &help,
+				Type: dto.MetricType_GAUGE.Enum(),
+			},
+		}
+		f[name] = mf
+	}
+
+	metric, err := prometheus.NewConstMetric(mf.desc, prometheus.GaugeValue, sample.Value, labelValues...)
+	if err != nil {
+		return err
+	}
+	m := &dto.Metric{}
+	if err := prometheus.NewMetricWithTimestamp(sample.Timestamp, metric).Write(m); err != nil {
+		return err
+	}
+	mf.family.Metric = append(mf.family.Metric, m)
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccremer/fronius-exporter/cfg"
+	"github.com/ccremer/fronius-exporter/pkg/fronius"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newArchiveServer returns a server that interprets the requested archive range in the given timezone like a Datamanager,
+// and records the raw StartDate and EndDate parameters.
+func newArchiveServer(t *testing.T, location *time.Location) (*httptest.Server, *[][2]string) {
+	var requested [][2]string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// The raw query is used, since the offset in the dates is not escaped.
+		_, rawStart, _ := strings.Cut(req.URL.RawQuery, "StartDate=")
+		rawStart, _, _ = strings.Cut(rawStart, "&")
+		_, rawEnd, _ := strings.Cut(req.URL.RawQuery, "EndDate=")
+		rawEnd, _, _ = strings.Cut(rawEnd, "&")
+		requested = append(requested, [2]string{rawStart, rawEnd})
+
+		// The device ignores the offset and takes the wall clock in its own timezone.
+		wallClock, err := time.Parse("2006-01-02T15:04:05", rawStart[:19])
+		require.NoError(t, err)
+		start := time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(), wallClock.Hour(), wallClock.Minute(), wallClock.Second(), 0, location)
+		response := map[string]interface{}{
+			"Body": map[string]interface{}{
+				"Data": map[string]interface{}{
+					"inverter/1": map[string]interface{}{
+						"Start": start.Format(time.RFC3339),
+						"Data": map[string]interface{}{
+							"Voltage_DC_String_1": map[string]interface{}{"Unit": "V", "Values": map[string]float64{"0": 425.6, "300": 426}},
+						},
+					},
+				},
+			},
+			"Head": map[string]interface{}{"Status": map[string]interface{}{"Code": 0}},
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requested
+}
+
+func Test_runBackfill_GivenNoTimezone_WhenHostInOtherTimezone_ThenUseDeviceTimezone(t *testing.T) {
+	hostLocation := time.Local
+	time.Local = time.UTC
+	t.Cleanup(func() { time.Local = hostLocation })
+
+	kathmandu := time.FixedZone("", 5*3600+45*60)
+	server, requested := newArchiveServer(t, kathmandu)
+	client, err := fronius.NewSymoClient(fronius.ClientOptions{
+		URL:             server.URL,
+		ArchiveChannels: []string{"Voltage_DC_String_1"},
+	})
+	require.NoError(t, err)
+
+	output := filepath.Join(t.TempDir(), "archive.om")
+	err = runBackfill(t.Context(), client, cfg.BackfillConfig{Start: "2024-01-01", End: "2024-01-01", Output: output})
+	require.NoError(t, err)
+
+	require.Len(t, *requested, 2, "the timezone should be detected before the range is requested")
+	assert.Equal(t, [2]string{"2024-01-01T00:00:00+05:45", "2024-01-01T23:59:59+05:45"}, (*requested)[1])
+	content, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `fronius_site_mppt_voltage{inverter="1",mppt="1"} 425.6 1.7040465e+09`)
+}
+
+func Test_parseBackfillTime(t *testing.T) {
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		value         string
+		location      *time.Location
+		endOfDay      bool
+		expected      time.Time
+		expectedError string
+	}{
+		"GivenDate_ThenStartOfDayInLocation": {
+			value:    "2024-07-01",
+			location: zurich,
+			expected: time.Date(2024, 7, 1, 0, 0, 0, 0, zurich),
+		},
+		"GivenDate_WhenEndOfDay_ThenLastSecondOfDayInLocation": {
+			value:    "2024-07-01",
+			location: zurich,
+			endOfDay: true,
+			expected: time.Date(2024, 7, 1, 23, 59, 59, 0, zurich),
+		},
+		"GivenDate_WhenEndOfDayAtDSTChange_ThenLastSecondOfDay": {
+			value:    "2024-03-31",
+			location: zurich,
+			endOfDay: true,
+			expected: time.Date(2024, 3, 31, 23, 59, 59, 0, zurich),
+		},
+		"GivenRFC3339_ThenIgnoreLocation": {
+			value:    "2024-07-01T12:30:00+05:45",
+			location: zurich,
+			expected: time.Date(2024, 7, 1, 12, 30, 0, 0, time.FixedZone("", 5*3600+45*60)),
+		},
+		"GivenRFC3339_WhenEndOfDay_ThenKeepTime": {
+			value:    "2024-07-01T12:30:00Z",
+			location: zurich,
+			endOfDay: true,
+			expected: time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC),
+		},
+		"GivenRFC3339_WhenNoLocation_ThenParse": {
+			value:    "2024-07-01T12:30:00Z",
+			expected: time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC),
+		},
+		"GivenDate_WhenNoLocation_ThenReturnError": {
+			value:         "2024-07-01",
+			expectedError: `the timezone of the device could not be detected, set --symo.timezone or give "2024-07-01" as RFC 3339 timestamp`,
+		},
+		"GivenEmptyValue_ThenReturnError": {
+			location:      zurich,
+			expectedError: "no date given",
+		},
+		"GivenInvalidDate_ThenReturnError": {
+			value:         "2024-13-01",
+			location:      zurich,
+			expectedError: `parsing time "2024-13-01"`,
+		},
+		"GivenDateWithoutZone_ThenReturnError": {
+			value:         "2024-07-01T12:30:00",
+			location:      zurich,
+			expectedError: `parsing time "2024-07-01T12:30:00"`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, err := parseBackfillTime(tt.value, tt.location, tt.endOfDay)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result), "expected %s, got %s", tt.expected, result)
+			_, expectedOffset := tt.expected.Zone()
+			_, offset := result.Zone()
+			assert.Equal(t, expectedOffset, offset)
+		})
+	}
+}
+
+// Test_writeOpenMetrics_ThenMatchGoldenFile compares the output with testdata/backfill.om.
+// The golden file can be checked for import with `promtool tsdb create-blocks-from openmetrics testdata/backfill.om <dir>`.
+func Test_writeOpenMetrics_ThenMatchGoldenFile(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := []fronius.ArchiveSeries{
+		{
+			Device:  "inverter/1",
+			Channel: "EnergyReal_WAC_Sum_Produced",
+			Unit:    "Wh",
+			Samples: []fronius.Sample{{Timestamp: start, Value: 1200}},
+		},
+		{
+			Device:  "inverter/1",
+			Channel: "Voltage_DC_String_1",
+			Unit:    "V",
+			Samples: []fronius.Sample{
+				// Timestamps are written with millisecond precision.
+				{Timestamp: start.Add(123 * time.Millisecond), Value: 425.6},
+				{Timestamp: start.Add(5 * time.Minute), Value: 426},
+			},
+		},
+		{
+			Device:  "inverter/2",
+			Channel: "Current_DC_String_2",
+			Unit:    "A",
+			Samples: []fronius.Sample{{Timestamp: start, Value: 3.25}},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, writeOpenMetrics(out, series))
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "backfill.om"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+	assert.True(t, strings.HasSuffix(out.String(), "\n# EOF\n"))
+}
+
+func Test_writeOpenMetrics_GivenNoSeries_ThenWriteEOF(t *testing.T) {
+	out := &bytes.Buffer{}
+	require.NoError(t, writeOpenMetrics(out, nil))
+	assert.Equal(t, "# EOF\n", out.String())
+}
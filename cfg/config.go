@@ -25,20 +25,50 @@ func ParseConfig(version, commit, date string, fs *flag.FlagSet, args []string)
 
 	postLoadProcess(config)
 
-	log.WithField("config", *config).Debug("Parsed config")
+	logged := *config
+	if logged.Symo.Password != "" {
+		logged.Symo.Password = "*****"
+	}
+	log.WithField("config", logged).Debug("Parsed config")
 	return config
 }
 
 func setupCliFlags(version string, fs *flag.FlagSet, config *Configuration) {
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage of %s (%s):\n", os.Args[0], version)
+		fmt.Fprintf(os.Stderr, "  %s [flags]            Run the exporter.\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s backfill [flags]   Write the archive history in OpenMetrics format and exit.\n", os.Args[0])
 		fs.PrintDefaults()
 	}
+	// Allow flags after the subcommand.
+	fs.SetInterspersed(true)
 	fs.String("bind-addr", config.BindAddr, "IP Address to bind to listen for Prometheus scrapes.")
+	fs.Duration("scrape-timeout-offset", config.ScrapeTimeoutOffset,
+		"Safety margin subtracted from the scrape timeout announced by Prometheus. Endpoints that are still pending at the resulting deadline are cut off.")
+	fs.String("backfill.start", config.Backfill.Start,
+		"Start of the range to backfill, as date (2006-01-02) or RFC 3339 timestamp. Required for the backfill command.")
+	fs.String("backfill.end", config.Backfill.End,
+		"End of the range to backfill, as date (inclusive) or RFC 3339 timestamp. Defaults to now.")
+	fs.String("backfill.output", config.Backfill.Output,
+		"File to write the backfill metrics to, \"-\" for stdout.")
+	fs.String("source", config.Source,
+		"Data source of the metrics: \"solarapi\" for the Fronius Solar API or \"modbus\" for SunSpec over Modbus TCP.")
+	fs.String("modbus.address", config.Modbus.Address,
+		"Host and port of the Modbus TCP server if the source is modbus. Defaults to the host of --symo.url on port 502.")
+	fs.Duration("modbus.timeout", config.Modbus.Timeout, "Timeout of each Modbus request.")
+	fs.StringSlice("modbus.unit-ids", config.Modbus.UnitIDs,
+		"List of Modbus unit IDs of the inverters and meters to read if the source is modbus. Fronius uses 1 for the first inverter and 240 for the primary meter, which needs to be added if installed.")
 	fs.String("log.level", config.Log.Level, "Logging level.")
 	fs.BoolP("log.verbose", "v", config.Log.Verbose, "Shortcut for --log.level=debug.")
 	fs.StringSlice("symo.header", config.Symo.Headers,
 		"List of \"key: value\" headers to append to the requests going to Fronius Symo. Example: --symo.header \"authorization=Basic <base64>\".")
+	fs.String("symo.username", config.Symo.Username, "Username for HTTP Digest authentication, required by some Gen24 firmware versions.")
+	fs.String("symo.password-file", config.Symo.PasswordFile, "Path to a file containing the password for HTTP Digest authentication.")
+	fs.String("symo.tls.ca-file", config.Symo.TLS.CAFile, "Path to a PEM bundle of certificate authorities to trust in addition to the system ones when connecting via HTTPS.")
+	fs.String("symo.tls.cert-file", config.Symo.TLS.CertFile, "Path to a PEM client certificate for mutual TLS. Requires --symo.tls.key-file.")
+	fs.String("symo.tls.key-file", config.Symo.TLS.KeyFile, "Path to the PEM key of the client certificate for mutual TLS.")
+	fs.String("symo.tls.server-name", config.Symo.TLS.ServerName, "Host name to verify the server certificate against instead of the host in --symo.url.")
+	fs.Bool("symo.tls.insecure-skip-verify", config.Symo.TLS.InsecureSkipVerify, "Disable verification of the server certificate. Insecure, use only for testing.")
 	fs.StringP("symo.url", "u", config.Symo.URL, "Target base URL of Fronius Symo device.")
 	fs.Int64("symo.timeout", int64(config.Symo.Timeout.Seconds()),
 		"Timeout in seconds when collecting metrics from Fronius Symo. Should not be larger than the scrape interval.")
@@ -46,6 +76,21 @@ func setupCliFlags(version string, fs *flag.FlagSet, config *Configuration) {
 	fs.Bool("symo.enable-archive", config.Symo.ArchiveEnabled, "Enable/disable scraping of archive data")
 	fs.Bool("symo.enable-inverter-realtime", config.Symo.InverterRealtimeEnabled, "Enable/disable scraping of inverter real time data")
 	fs.Bool("symo.enable-meter-realtime", config.Symo.MeterRealtimeEnabled, "Enable/disable scraping of meter real time data")
+	fs.Bool("symo.enable-device-info", config.Symo.DeviceInfoEnabled, "Enable/disable scraping of active device info")
+	fs.Bool("symo.enable-inverter-info", config.Symo.InverterInfoEnabled, "Enable/disable scraping of inverter info and status")
+	fs.Bool("symo.enable-storage-realtime", config.Symo.StorageRealtimeEnabled, "Enable/disable scraping of storage (battery) real time data")
+	fs.Bool("symo.enable-ohmpilot-realtime", config.Symo.OhmpilotRealtimeEnabled, "Enable/disable scraping of Ohmpilot real time data")
+	fs.Bool("symo.enable-sensor-realtime", config.Symo.SensorRealtimeEnabled, "Enable/disable scraping of sensor card real time data")
+	fs.StringSlice("symo.inverter-data-collections", config.Symo.InverterDataCollections,
+		"List of data collections to scrape from each inverter if inverter real time data is enabled. Supported: CommonInverterData, 3PInverterData, MinMaxInverterData.")
+	fs.StringSlice("symo.archive-channels", config.Symo.ArchiveChannels,
+		"List of channels to scrape from the archive if archive data is enabled. Examples: Temperature_Powerstage, EnergyReal_WAC_Sum_Produced, PowerReal_PAC_Sum, Voltage_DC_String_3.")
+	fs.String("symo.timezone", config.Symo.Timezone,
+		"Timezone configured in Fronius Symo, e.g. Europe/Zurich. Archive requests are made in this timezone. Detected from the archive responses if empty.")
+	fs.Int("symo.retries", config.Symo.Retries,
+		"Number of times a request to Fronius Symo is repeated after a transient error like a dropped connection. Retries stop at the scrape deadline.")
+	fs.Duration("symo.retry-backoff", config.Symo.RetryBackoff,
+		"Delay before the first retry of a failed request. It is doubled with each further retry and randomized by jitter.")
 }
 
 func postLoadProcess(config *Configuration) {
@@ -60,6 +105,32 @@ func postLoadProcess(config *Configuration) {
 	}
 	config.Symo.Headers = parsedHeaders
 
+	if config.Symo.PasswordFile != "" {
+		password, err := os.ReadFile(config.Symo.PasswordFile)
+		if err != nil {
+			log.WithError(err).Fatal("Could not read password file")
+		}
+		config.Symo.Password = strings.TrimSpace(string(password))
+	}
+
+	var parsedCollections []string
+	for _, collection := range config.Symo.InverterDataCollections {
+		parsedCollections = splitHeaderStrings(collection, parsedCollections)
+	}
+	config.Symo.InverterDataCollections = parsedCollections
+
+	var parsedChannels []string
+	for _, channel := range config.Symo.ArchiveChannels {
+		parsedChannels = splitHeaderStrings(channel, parsedChannels)
+	}
+	config.Symo.ArchiveChannels = parsedChannels
+
+	var parsedUnitIDs []string
+	for _, unitID := range config.Modbus.UnitIDs {
+		parsedUnitIDs = splitHeaderStrings(unitID, parsedUnitIDs)
+	}
+	config.Modbus.UnitIDs = parsedUnitIDs
+
 	level, err := log.ParseLevel(config.Log.Level)
 	if err != nil {
 		log.WithError(err).Warn("Could not parse log level, fallback to info level")
@@ -111,6 +182,7 @@ func loadConfigHierarchy(fs *flag.FlagSet, args []string, config *Configuration)
 	if err := koanfInstance.Unmarshal("", &config); err != nil {
 		log.WithError(err).Fatal("Could not merge defaults with settings from environment variables")
 	}
+	config.Command = fs.Arg(0)
 }
 
 // ConvertHeaders takes a list of `key=value` headers and adds those trimmed to the specified header struct. It ignores
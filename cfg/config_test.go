@@ -141,12 +141,108 @@ func TestParseConfig(t *testing.T) {
 				assert.Equal(t, "myurl", c.Symo.URL)
 			},
 		},
+		"GivenScrapeTimeoutOffsetFlag_WhenSpecified_ThenOverrideDefault": {
+			args: []string{"--scrape-timeout-offset", "1.5s"},
+			verify: func(c *Configuration) {
+				assert.Equal(t, 1500*time.Millisecond, c.ScrapeTimeoutOffset)
+			},
+		},
+		"GivenNoScrapeTimeoutOffsetFlag_ThenUseDefault": {
+			verify: func(c *Configuration) {
+				assert.Equal(t, 500*time.Millisecond, c.ScrapeTimeoutOffset)
+			},
+		},
 		"GivenTimeoutFlag_WhenSpecified_ThenOverrideDefault": {
 			args: []string{"--symo.timeout", "3"},
 			verify: func(c *Configuration) {
 				assert.Equal(t, 3*time.Second, c.Symo.Timeout)
 			},
 		},
+		"GivenPasswordFile_WhenSpecified_ThenReadPassword": {
+			args: []string{"--symo.username", "customer", "--symo.password-file", "testdata/password"},
+			verify: func(c *Configuration) {
+				assert.Equal(t, "customer", c.Symo.Username)
+				assert.Equal(t, "secret", c.Symo.Password)
+			},
+		},
+		"GivenTLSFlags_WhenSpecified_ThenOverrideDefault": {
+			args: []string{"--symo.tls.ca-file", "ca.pem", "--symo.tls.server-name", "symo.local", "--symo.tls.insecure-skip-verify"},
+			verify: func(c *Configuration) {
+				assert.Equal(t, "ca.pem", c.Symo.TLS.CAFile)
+				assert.Equal(t, "symo.local", c.Symo.TLS.ServerName)
+				assert.True(t, c.Symo.TLS.InsecureSkipVerify)
+			},
+		},
+		"GivenTLSEnvVar_WhenSpecified_ThenOverrideDefault": {
+			envs: map[string]string{
+				"SYMO__TLS__CERT_FILE": "client.pem",
+			},
+			verify: func(c *Configuration) {
+				assert.Equal(t, "client.pem", c.Symo.TLS.CertFile)
+			},
+		},
+		"GivenArchiveChannelsEnvVar_WhenMultipleChannelsSpecified_ThenFillArray": {
+			envs: map[string]string{
+				"SYMO__ARCHIVE_CHANNELS": "Temperature_Powerstage, PowerReal_PAC_Sum",
+			},
+			verify: func(c *Configuration) {
+				assert.Equal(t, []string{"Temperature_Powerstage", "PowerReal_PAC_Sum"}, c.Symo.ArchiveChannels)
+			},
+		},
+		"GivenBackfillCommand_WhenFlagsSpecified_ThenSetCommandAndRange": {
+			args: []string{"backfill", "--backfill.start", "2024-01-01", "--backfill.end", "2024-01-31"},
+			verify: func(c *Configuration) {
+				assert.Equal(t, "backfill", c.Command)
+				assert.Equal(t, "2024-01-01", c.Backfill.Start)
+				assert.Equal(t, "2024-01-31", c.Backfill.End)
+				assert.Equal(t, "-", c.Backfill.Output)
+			},
+		},
+		"GivenNoCommand_ThenLeaveCommandEmpty": {
+			verify: func(c *Configuration) {
+				assert.Empty(t, c.Command)
+			},
+		},
+		"GivenTimezoneEnvVar_WhenSpecified_ThenOverrideDefault": {
+			envs: map[string]string{
+				"SYMO__TIMEZONE": "Europe/Zurich",
+			},
+			verify: func(c *Configuration) {
+				assert.Equal(t, "Europe/Zurich", c.Symo.Timezone)
+			},
+		},
+		"GivenModbusSource_WhenFlagsSpecified_ThenOverrideDefault": {
+			args: []string{"--source=modbus", "--modbus.address=symo:1502", "--modbus.timeout=2s"},
+			verify: func(c *Configuration) {
+				assert.Equal(t, SourceModbus, c.Source)
+				assert.Equal(t, "symo:1502", c.Modbus.Address)
+				assert.Equal(t, 2*time.Second, c.Modbus.Timeout)
+				assert.Equal(t, []string{"1"}, c.Modbus.UnitIDs)
+			},
+		},
+		"GivenModbusUnitIDsEnvVar_WhenMultipleIDsSpecified_ThenFillArray": {
+			envs: map[string]string{
+				"MODBUS__UNIT_IDS": "1, 2,241",
+			},
+			verify: func(c *Configuration) {
+				assert.Equal(t, []string{"1", "2", "241"}, c.Modbus.UnitIDs)
+			},
+		},
+		"GivenRetryFlags_WhenSpecified_ThenOverrideDefault": {
+			args: []string{"--symo.retries", "5", "--symo.retry-backoff", "1s"},
+			verify: func(c *Configuration) {
+				assert.Equal(t, 5, c.Symo.Retries)
+				assert.Equal(t, time.Second, c.Symo.RetryBackoff)
+			},
+		},
+		"GivenInverterDataCollectionsEnvVar_WhenMultipleCollectionsSpecified_ThenFillArray": {
+			envs: map[string]string{
+				"SYMO__INVERTER_DATA_COLLECTIONS": "CommonInverterData, 3PInverterData",
+			},
+			verify: func(c *Configuration) {
+				assert.Equal(t, []string{"CommonInverterData", "3PInverterData"}, c.Symo.InverterDataCollections)
+			},
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
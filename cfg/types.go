@@ -2,12 +2,39 @@ package cfg
 
 import "time"
 
+const (
+	// SourceSolarAPI selects the Fronius Solar API as data source
+	SourceSolarAPI = "solarapi"
+	// SourceModbus selects SunSpec over Modbus TCP as data source
+	SourceModbus = "modbus"
+)
+
 type (
 	// Configuration holds a strongly-typed tree of the configuration
 	Configuration struct {
 		Log      LogConfig  `koanf:"log"`
 		Symo     SymoConfig `koanf:"symo"`
 		BindAddr string     `koanf:"bind-addr"`
+		// Source is the data source of the metrics, either SourceSolarAPI or SourceModbus.
+		Source string       `koanf:"source"`
+		Modbus ModbusConfig `koanf:"modbus"`
+		// ScrapeTimeoutOffset is subtracted from the scrape timeout sent by Prometheus to leave time for the response.
+		ScrapeTimeoutOffset time.Duration  `koanf:"scrape-timeout-offset"`
+		Backfill            BackfillConfig `koanf:"backfill"`
+		// Command is the subcommand given as first argument. It is empty when running the exporter.
+		Command string `koanf:"-"`
+	}
+	// BackfillConfig configures the backfill command
+	BackfillConfig struct {
+		Start  string `koanf:"start"`
+		End    string `koanf:"end"`
+		Output string `koanf:"output"`
+	}
+	// ModbusConfig configures the SunSpec data source over Modbus TCP
+	ModbusConfig struct {
+		Address string        `koanf:"address"`
+		Timeout time.Duration `koanf:"timeout"`
+		UnitIDs []string      `koanf:"unit-ids"`
 	}
 	// LogConfig configures the logging options
 	LogConfig struct {
@@ -16,13 +43,36 @@ type (
 	}
 	// SymoConfig configures the Fronius Symo device
 	SymoConfig struct {
-		URL                     string        `koanf:"url"`
-		Timeout                 time.Duration `koanf:"timeout"`
-		Headers                 []string      `koanf:"header"`
+		URL          string        `koanf:"url"`
+		Timeout      time.Duration `koanf:"timeout"`
+		Headers      []string      `koanf:"header"`
+		Username     string        `koanf:"username"`
+		PasswordFile string        `koanf:"password-file"`
+		// Password is read from PasswordFile.
+		Password                string        `koanf:"-"`
 		PowerFlowEnabled        bool          `koanf:"enable-power-flow"`
 		ArchiveEnabled          bool          `koanf:"enable-archive"`
 		InverterRealtimeEnabled bool          `koanf:"enable-inverter-realtime"`
 		MeterRealtimeEnabled    bool          `koanf:"enable-meter-realtime"`
+		DeviceInfoEnabled       bool          `koanf:"enable-device-info"`
+		InverterInfoEnabled     bool          `koanf:"enable-inverter-info"`
+		StorageRealtimeEnabled  bool          `koanf:"enable-storage-realtime"`
+		OhmpilotRealtimeEnabled bool          `koanf:"enable-ohmpilot-realtime"`
+		SensorRealtimeEnabled   bool          `koanf:"enable-sensor-realtime"`
+		InverterDataCollections []string      `koanf:"inverter-data-collections"`
+		ArchiveChannels         []string      `koanf:"archive-channels"`
+		Timezone                string        `koanf:"timezone"`
+		Retries                 int           `koanf:"retries"`
+		RetryBackoff            time.Duration `koanf:"retry-backoff"`
+		TLS                     TLSConfig     `koanf:"tls"`
+	}
+	// TLSConfig configures the TLS connection to the Fronius Symo device
+	TLSConfig struct {
+		CAFile             string `koanf:"ca-file"`
+		CertFile           string `koanf:"cert-file"`
+		KeyFile            string `koanf:"key-file"`
+		ServerName         string `koanf:"server-name"`
+		InsecureSkipVerify bool   `koanf:"insecure-skip-verify"`
 	}
 )
 
@@ -40,7 +90,22 @@ func NewDefaultConfig() *Configuration {
 			ArchiveEnabled:          true,
 			InverterRealtimeEnabled: true,
 			MeterRealtimeEnabled:    true,
+			DeviceInfoEnabled:       true,
+			InverterInfoEnabled:     true,
+			InverterDataCollections: []string{"CommonInverterData"},
+			ArchiveChannels:         []string{"Voltage_DC_String_1", "Current_DC_String_1", "Voltage_DC_String_2", "Current_DC_String_2"},
+			Retries:                 2,
+			RetryBackoff:            250 * time.Millisecond,
 		},
 		BindAddr: ":8080",
+		Source:   SourceSolarAPI,
+		Modbus: ModbusConfig{
+			Timeout: 5 * time.Second,
+			UnitIDs: []string{"1"},
+		},
+		ScrapeTimeoutOffset: 500 * time.Millisecond,
+		Backfill: BackfillConfig{
+			Output: "-",
+		},
 	}
 }
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/ccremer/fronius-exporter/cfg"
 	"github.com/ccremer/fronius-exporter/pkg/fronius"
+	"github.com/ccremer/fronius-exporter/pkg/sunspec"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 	flag "github.com/spf13/pflag"
@@ -29,20 +34,76 @@ func main() {
 
 	headers := http.Header{}
 	cfg.ConvertHeaders(config.Symo.Headers, &headers)
+	var location *time.Location
+	if config.Symo.Timezone != "" {
+		loc, err := time.LoadLocation(config.Symo.Timezone)
+		if err != nil {
+			log.WithError(err).Fatal("Cannot load timezone of Fronius Symo.")
+		}
+		location = loc
+	}
 	symoClient, err := fronius.NewSymoClient(fronius.ClientOptions{
-		URL:                     config.Symo.URL,
-		Headers:                 headers,
-		Timeout:                 config.Symo.Timeout,
+		URL:      config.Symo.URL,
+		Headers:  headers,
+		Timeout:  config.Symo.Timeout,
+		Username: config.Symo.Username,
+		Password: config.Symo.Password,
+		TLS: fronius.TLSOptions{
+			CAFile:             config.Symo.TLS.CAFile,
+			CertFile:           config.Symo.TLS.CertFile,
+			KeyFile:            config.Symo.TLS.KeyFile,
+			ServerName:         config.Symo.TLS.ServerName,
+			InsecureSkipVerify: config.Symo.TLS.InsecureSkipVerify,
+		},
 		PowerFlowEnabled:        config.Symo.PowerFlowEnabled,
 		ArchiveEnabled:          config.Symo.ArchiveEnabled,
 		InverterRealtimeEnabled: config.Symo.InverterRealtimeEnabled,
 		MeterRealtimeEnabled:    config.Symo.MeterRealtimeEnabled,
+		DeviceInfoEnabled:       config.Symo.DeviceInfoEnabled,
+		InverterInfoEnabled:     config.Symo.InverterInfoEnabled,
+		StorageRealtimeEnabled:  config.Symo.StorageRealtimeEnabled,
+		OhmpilotRealtimeEnabled: config.Symo.OhmpilotRealtimeEnabled,
+		SensorRealtimeEnabled:   config.Symo.SensorRealtimeEnabled,
+		InverterDataCollections: config.Symo.InverterDataCollections,
+		ArchiveChannels:         config.Symo.ArchiveChannels,
+		Location:                location,
+		Retries:                 config.Symo.Retries,
+		RetryBackoff:            config.Symo.RetryBackoff,
+		OnRetry:                 countRetry,
 	})
 	if err != nil {
 		log.WithError(err).Fatal("Cannot initialize Fronius Symo client.")
 	}
-	if !config.Symo.ArchiveEnabled && !config.Symo.PowerFlowEnabled && !config.Symo.InverterRealtimeEnabled && !config.Symo.MeterRealtimeEnabled {
-		log.Fatal("All scrape endpoints are disabled. You need enable at least one endpoint.")
+
+	switch config.Command {
+	case "":
+	case "backfill":
+		if err := runBackfill(context.Background(), symoClient, config.Backfill); err != nil {
+			log.WithError(err).Fatal("Could not backfill archive data.")
+		}
+		return
+	default:
+		log.WithField("command", config.Command).Fatal("Unknown command.")
+	}
+
+	var collect func(ctx context.Context)
+	switch config.Source {
+	case cfg.SourceSolarAPI:
+		if !config.Symo.ArchiveEnabled && !config.Symo.PowerFlowEnabled && !config.Symo.InverterRealtimeEnabled && !config.Symo.MeterRealtimeEnabled &&
+			!config.Symo.DeviceInfoEnabled && !config.Symo.InverterInfoEnabled && !config.Symo.StorageRealtimeEnabled &&
+			!config.Symo.OhmpilotRealtimeEnabled && !config.Symo.SensorRealtimeEnabled {
+			log.Fatal("All scrape endpoints are disabled. You need enable at least one endpoint.")
+		}
+		collect = func(ctx context.Context) {
+			collectMetricsFromTarget(ctx, symoClient)
+		}
+	case cfg.SourceModbus:
+		sunSpecClient := newSunSpecClient(config)
+		collect = func(ctx context.Context) {
+			collectSunSpecMetrics(ctx, sunSpecClient)
+		}
+	default:
+		log.WithField("source", config.Source).Fatal("Unknown source.")
 	}
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -68,10 +129,61 @@ func main() {
 			"uri":    r.RequestURI,
 			"client": r.RemoteAddr,
 		}).Debug("Accessed Metrics endpoint")
-		collectMetricsFromTarget(symoClient)
+		ctx, cancel := scrapeContext(r, config.ScrapeTimeoutOffset)
+		defer cancel()
+		collect(ctx)
 		promHandler.ServeHTTP(w, r)
 	})
 
 	log.WithField("port", config.BindAddr).Info("Listening for scrapes.")
 	log.WithError(http.ListenAndServe(config.BindAddr, nil)).Fatal("Shutting down.")
 }
+
+// newSunSpecClient returns a client for the Modbus TCP server of the device.
+// Without an address, the host of the Solar API URL is used with the default Modbus port.
+func newSunSpecClient(config *cfg.Configuration) *sunspec.Client {
+	address := config.Modbus.Address
+	if address == "" {
+		symoURL, err := url.Parse(config.Symo.URL)
+		if err != nil {
+			log.WithError(err).Fatal("Cannot derive Modbus address from URL of Fronius Symo.")
+		}
+		address = net.JoinHostPort(symoURL.Hostname(), "502")
+	}
+	unitIDs := make([]uint8, 0, len(config.Modbus.UnitIDs))
+	for _, id := range config.Modbus.UnitIDs {
+		unitID, err := strconv.ParseUint(id, 10, 8)
+		if err != nil {
+			log.WithError(err).WithField("unitID", id).Fatal("Invalid Modbus unit ID.")
+		}
+		unitIDs = append(unitIDs, uint8(unitID))
+	}
+	client, err := sunspec.NewClient(sunspec.ClientOptions{
+		Address: address,
+		Timeout: config.Modbus.Timeout,
+		UnitIDs: unitIDs,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Cannot initialize SunSpec client.")
+	}
+	return client
+}
+
+// scrapeContext returns a context derived from the scrape request that expires the given offset before Prometheus gives up on the scrape.
+// If Prometheus doesn't send its scrape timeout, the context is only cancelled with the request.
+func scrapeContext(r *http.Request, offset time.Duration) (context.Context, context.CancelFunc) {
+	header := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if header == "" {
+		return context.WithCancel(r.Context())
+	}
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		log.WithField("header", header).Debug("Could not parse scrape timeout header, ignoring")
+		return context.WithCancel(r.Context())
+	}
+	timeout := time.Duration(seconds * float64(time.Second))
+	if timeout > offset {
+		timeout -= offset
+	}
+	return context.WithTimeout(r.Context(), timeout)
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,6 +27,27 @@ var (
 		Name:      "scrape_error_count",
 		Help:      "Number of scrape errors",
 	})
+	scrapeTimeoutCountVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scrape_timeout_count",
+		Help:      "Number of endpoint requests that were cut off by the scrape deadline",
+	}, []string{"endpoint"})
+	scrapeHTTPErrorCountVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scrape_http_error_count",
+		Help:      "Number of endpoint requests that failed with an unexpected HTTP status or content type",
+	}, []string{"endpoint", "status_code"})
+	scrapeRetryCountVec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "scrape_retry_count",
+		Help:      "Number of retried requests per Solar API endpoint",
+	}, []string{"endpoint"})
+
+	deviceInfoGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "device_info",
+		Help:      "Devices attached to the Fronius Datamanager. The value is always 1",
+	}, []string{"device_class", "device_id", "device_type", "serial"})
 
 	inverterPowerGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
@@ -34,38 +59,59 @@ var (
 		Name:      "inverter_soc",
 		Help:      "State of charge of the battery attached to the inverter in percent",
 	}, []string{"inverter"})
+	inverterInfoGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_info",
+		Help:      "Static information of the inverter. The value is always 1",
+	}, []string{"inverter", "custom_name", "unique_id"})
+	inverterStatusCodeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_status_code",
+		Help:      "Status code of the inverter, the state label contains the decoded operating state",
+	}, []string{"inverter", "state"})
+	inverterErrorCodeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_error_code",
+		Help:      "Error code of the inverter, 0 if there is no error",
+	}, []string{"inverter"})
+	inverterPVPowerGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_pv_power",
+		Help:      "Installed peak power of the solar panels attached to the inverter in Watt",
+	}, []string{"inverter"})
 
-	sitePowerLoadGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	// The site metrics have no labels, but are vectors so that they can be deleted if the device doesn't report a value.
+	sitePowerLoadGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_power_load",
 		Help:      "Site power load in Watt",
-	})
-	sitePowerGridGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, nil)
+	sitePowerGridGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_power_grid",
 		Help:      "Site power supplied to or provided from the grid in Watt",
-	})
-	sitePowerAccuGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, nil)
+	sitePowerAccuGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_power_accu",
 		Help:      "Site power supplied to or provided from the accumulator(s) in Watt",
-	})
-	sitePowerPhotovoltaicsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, nil)
+	sitePowerPhotovoltaicsGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_power_photovoltaic",
 		Help:      "Site power supplied to or provided from the accumulator(s) in Watt",
-	})
+	}, nil)
 
-	siteAutonomyRatioGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	siteAutonomyRatioGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_autonomy_ratio",
 		Help:      "Relative autonomy ratio of the site",
-	})
-	siteSelfConsumptionRatioGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, nil)
+	siteSelfConsumptionRatioGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_selfconsumption_ratio",
 		Help:      "Relative self consumption ratio of the site",
-	})
+	}, nil)
 
 	siteEnergyGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
@@ -73,86 +119,364 @@ var (
 		Help:      "Energy consumption in kWh",
 	}, []string{"time_frame"})
 
-	siteMPPTVoltageGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	// The archive metrics are also written by the backfill command, hence their options are shared.
+	siteMPPTLabels      = []string{"inverter", "mppt"}
+	siteMPPTVoltageOpts = prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_mppt_voltage",
 		Help:      "Site mppt voltage in V",
-	}, []string{"inverter", "mppt"})
+	}
+	siteMPPTVoltageGaugeVec = promauto.NewGaugeVec(siteMPPTVoltageOpts, siteMPPTLabels)
 
-	siteMPPTCurrentDCGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	siteMPPTCurrentDCOpts = prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_mppt_current_dc",
 		Help:      "Site mppt current DC in A",
-	}, []string{"inverter", "mppt"})
+	}
+	siteMPPTCurrentDCGaugeVec = promauto.NewGaugeVec(siteMPPTCurrentDCOpts, siteMPPTLabels)
+
+	archiveChannelLabels = []string{"inverter", "channel", "unit"}
+	archiveChannelOpts   = prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "archive_channel",
+		Help:      "Latest value of the archive channel of the inverter in the given unit",
+	}
+	archiveChannelGaugeVec = promauto.NewGaugeVec(archiveChannelOpts, archiveChannelLabels)
 
-	siteRealtimeDataDcCurrentMPPT1Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	siteRealtimeDataDcCurrentMPPT1GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_current_mppt1",
 		Help:      "Site real time data DC current MPPT 1 in A",
-	})
-	siteRealtimeDataDcCurrentMPPT2Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataDcCurrentMPPT2GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_current_mppt2",
 		Help:      "Site real time data DC current MPPT 2 in A",
-	})
-	siteRealtimeDataDcCurrentMPPT3Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataDcCurrentMPPT3GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_current_mppt3",
 		Help:      "Site real time data DC current MPPT 3 in A",
-	})
-	siteRealtimeDataDcCurrentMPPT4Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataDcCurrentMPPT4GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_current_mppt4",
 		Help:      "Site real time data DC current MPPT 4 in A",
-	})
-	siteRealtimeDataDcVoltageMPPT1Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataDcVoltageMPPT1GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_voltage_mppt1",
 		Help:      "Site real time data DC voltage MPPT 1 in V",
-	})
-	siteRealtimeDataDcVoltageMPPT2Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataDcVoltageMPPT2GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_voltage_mppt2",
 		Help:      "Site real time data DC voltage MPPT 2 in V",
-	})
-	siteRealtimeDataDcVoltageMPPT3Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataDcVoltageMPPT3GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_voltage_mppt3",
 		Help:      "Site real time data DC voltage MPPT 3 in V",
-	})
-	siteRealtimeDataDcVoltageMPPT4Gauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataDcVoltageMPPT4GaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_dc_voltage_mppt4",
 		Help:      "Site real time data DC voltage MPPT 4 in V",
-	})
-	siteRealtimeDataAcFrequencyGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataAcFrequencyGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_ac_frequency",
 		Help:      "Site real time data AC frequency in Hz",
-	})
-	siteRealtimeDataAcPowerGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataAcPowerGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_ac_power",
 		Help:      "Site real time data AC power in W",
-	})
-	siteRealtimeDataTotalEnergyGeneratedGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	siteRealtimeDataTotalEnergyGeneratedGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_realtime_data_total_energy_generated",
 		Help:      "Site real time data total energy generated in Wh",
-	})
-	siteMeterRealTimeDataEnergyReal_WAC_Sum_Produced = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"inverter"})
+	inverterAcCurrentGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_ac_current",
+		Help:      "AC current per phase of a three-phase inverter in A",
+	}, []string{"inverter", "phase"})
+	inverterAcVoltageGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_ac_voltage",
+		Help:      "AC voltage between phase and neutral of a three-phase inverter in V",
+	}, []string{"inverter", "phase"})
+	inverterPowerExtremeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_power_extreme",
+		Help:      "Extreme AC power of the inverter within the period in W",
+	}, []string{"inverter", "period", "kind"})
+	inverterAcVoltageExtremeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_ac_voltage_extreme",
+		Help:      "Extreme AC voltage of the inverter within the period in V",
+	}, []string{"inverter", "period", "kind"})
+	inverterDcVoltageExtremeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "inverter_dc_voltage_extreme",
+		Help:      "Extreme DC voltage of the inverter within the period in V",
+	}, []string{"inverter", "period", "kind"})
+
+	siteMeterRealTimeDataEnergyReal_WAC_Sum_Produced = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_meter_real_time_data_energy_real_wac_sum_produced",
 		Help:      "Site meter real time data energy real WAC sum produced in Wh",
-	})
-	siteMeterRealTimeDataEnergyReal_WAC_Sum_Consumed = promauto.NewGauge(prometheus.GaugeOpts{
+	}, []string{"meter", "location"})
+	siteMeterRealTimeDataEnergyReal_WAC_Sum_Consumed = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: namespace,
 		Name:      "site_meter_real_time_data_energy_real_wac_sum_consumed",
 		Help:      "Site meter real time data energy real WAC sum consumed in Wh",
-	})
+	}, []string{"meter", "location"})
+	siteMeterVoltageGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_meter_voltage",
+		Help:      "Site meter AC voltage between phase and neutral in V",
+	}, []string{"meter", "location", "phase"})
+	siteMeterCurrentGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_meter_current",
+		Help:      "Site meter AC current in A",
+	}, []string{"meter", "location", "phase"})
+	siteMeterPowerRealGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_meter_power_real",
+		Help:      "Site meter active power in W",
+	}, []string{"meter", "location", "phase"})
+	siteMeterPowerReactiveGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_meter_power_reactive",
+		Help:      "Site meter reactive power in VAr",
+	}, []string{"meter", "location", "phase"})
+	siteMeterPowerApparentGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_meter_power_apparent",
+		Help:      "Site meter apparent power in VA",
+	}, []string{"meter", "location", "phase"})
+	siteMeterPowerFactorGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_meter_power_factor",
+		Help:      "Site meter power factor",
+	}, []string{"meter", "location", "phase"})
+	siteMeterFrequencyGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_meter_frequency",
+		Help:      "Site meter grid frequency averaged over all phases in Hz",
+	}, []string{"meter", "location"})
+
+	siteMeterEnergyRegisters = newMeterEnergyCollector()
+
+	storageInfoGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_info",
+		Help:      "Static information of the storage. The value is always 1",
+	}, []string{"storage", "manufacturer", "model", "serial"})
+	storageStatusCodeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_status_code",
+		Help:      "Manufacturer-specific state code of the storage controller",
+	}, []string{"storage"})
+	storageChargeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_soc",
+		Help:      "State of charge of the storage, 1 meaning fully charged",
+	}, []string{"storage"})
+	storageCapacityMaximumGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_capacity_maximum",
+		Help:      "Currently usable capacity of the storage in Wh",
+	}, []string{"storage"})
+	storageCapacityDesignedGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_capacity_designed",
+		Help:      "Nominal capacity of the storage in Wh",
+	}, []string{"storage"})
+	storageCycleCountGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_cycle_count",
+		Help:      "Number of full charge cycles of the storage cells",
+	}, []string{"storage"})
+	storageCurrentDCGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_current_dc",
+		Help:      "DC current of the storage in A",
+	}, []string{"storage"})
+	storageVoltageDCGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_voltage_dc",
+		Help:      "DC voltage of the storage in V",
+	}, []string{"storage"})
+	storageCellTemperatureGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_cell_temperature",
+		Help:      "Temperature of the storage cells in degree Celsius",
+	}, []string{"storage", "kind"})
+
+	ohmpilotInfoGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ohmpilot_info",
+		Help:      "Static information of the Ohmpilot. The value is always 1",
+	}, []string{"ohmpilot", "model", "serial"})
+	ohmpilotStateCodeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ohmpilot_state_code",
+		Help:      "State code of the Ohmpilot, the state label contains the decoded operating state",
+	}, []string{"ohmpilot", "state"})
+	ohmpilotErrorCodeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ohmpilot_error_code",
+		Help:      "Error code of the Ohmpilot, 0 if there is no error",
+	}, []string{"ohmpilot"})
+	ohmpilotPowerGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ohmpilot_power",
+		Help:      "Power consumption of the Ohmpilot in W",
+	}, []string{"ohmpilot"})
+	ohmpilotTemperatureGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ohmpilot_temperature",
+		Help:      "Temperature measured by the Ohmpilot sensor in degree Celsius",
+	}, []string{"ohmpilot"})
+	ohmpilotEnergyConsumed = newOhmpilotEnergyCollector()
+
+	sensorChannelGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sensor_channel",
+		Help:      "Current value of a sensor card channel in the unit given by the unit label",
+	}, []string{"sensor_card", "channel", "name", "unit"})
+	sensorChannelExtremeGaugeVec = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "sensor_channel_extreme",
+		Help:      "Minimum or maximum value of a sensor card channel within the period in the unit given by the unit label",
+	}, []string{"sensor_card", "channel", "name", "unit", "period", "kind"})
 )
 
-func collectMetricsFromTarget(client *fronius.SymoClient) {
+// meterEnergyCollector exposes the energy registers of the smart meters as counters.
+// The registers are maintained by the meters themselves, so the collector only reports the last values read.
+type meterEnergyCollector struct {
+	mu     sync.Mutex
+	meters map[string]meterEnergy
+
+	realConsumedDesc     *prometheus.Desc
+	realProducedDesc     *prometheus.Desc
+	reactiveConsumedDesc *prometheus.Desc
+	reactiveProducedDesc *prometheus.Desc
+}
+
+func newMeterEnergyCollector() *meterEnergyCollector {
+	c := &meterEnergyCollector{
+		meters: map[string]meterEnergy{},
+		realConsumedDesc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "site_meter_energy_real_consumed_total"),
+			"Site meter active energy consumed per phase in Wh", []string{"meter", "location", "phase"}, nil),
+		realProducedDesc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "site_meter_energy_real_produced_total"),
+			"Site meter active energy produced per phase in Wh", []string{"meter", "location", "phase"}, nil),
+		reactiveConsumedDesc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "site_meter_energy_reactive_consumed_total"),
+			"Site meter reactive energy consumed in VArh", []string{"meter", "location"}, nil),
+		reactiveProducedDesc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "site_meter_energy_reactive_produced_total"),
+			"Site meter reactive energy produced in VArh", []string{"meter", "location"}, nil),
+	}
+	prometheus.MustRegister(c)
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *meterEnergyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.realConsumedDesc
+	ch <- c.realProducedDesc
+	ch <- c.reactiveConsumedDesc
+	ch <- c.reactiveProducedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *meterEnergyCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for meterID, energy := range c.meters {
+		for i, phase := range []string{"1", "2", "3"} {
+			c.collectOptional(ch, c.realConsumedDesc, energy.realConsumed[i], meterID, energy.location, phase)
+			c.collectOptional(ch, c.realProducedDesc, energy.realProduced[i], meterID, energy.location, phase)
+		}
+		c.collectOptional(ch, c.reactiveConsumedDesc, energy.reactiveConsumed, meterID, energy.location)
+		c.collectOptional(ch, c.reactiveProducedDesc, energy.reactiveProduced, meterID, energy.location)
+	}
+}
+
+// collectOptional sends the counter with the given label values, unless the meter didn't report the value.
+func (c *meterEnergyCollector) collectOptional(ch chan<- prometheus.Metric, desc *prometheus.Desc, value *float64, labelValues ...string) {
+	if value != nil {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, *value, labelValues...)
+	}
+}
+
+// update stores the latest energy registers of the given meter.
+func (c *meterEnergyCollector) update(meterID string, energy meterEnergy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.meters[meterID] = energy
+}
+
+// reset removes the energy registers of all meters.
+func (c *meterEnergyCollector) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.meters = map[string]meterEnergy{}
+}
+
+// meterEnergy holds the energy registers of a meter at its installation point.
+// Registers that the meter doesn't report are nil.
+type meterEnergy struct {
+	location                           string
+	realConsumed, realProduced         [3]*float64
+	reactiveConsumed, reactiveProduced *float64
+}
+
+// ohmpilotEnergyCollector exposes the energy consumed by the Ohmpilots as counter.
+// Like the meter registers, the energy is accumulated by the Ohmpilot itself, so the collector only reports the last values read.
+type ohmpilotEnergyCollector struct {
+	mu        sync.Mutex
+	ohmpilots map[string]float64
+
+	desc *prometheus.Desc
+}
+
+func newOhmpilotEnergyCollector() *ohmpilotEnergyCollector {
+	c := &ohmpilotEnergyCollector{
+		ohmpilots: map[string]float64{},
+		desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "ohmpilot_energy_consumed_total"),
+			"Energy consumed by the Ohmpilot in Wh", []string{"ohmpilot"}, nil),
+	}
+	prometheus.MustRegister(c)
+	return c
+}
+
+// Describe implements prometheus.Collector.
+func (c *ohmpilotEnergyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *ohmpilotEnergyCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ohmpilotID, energy := range c.ohmpilots {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, energy, ohmpilotID)
+	}
+}
+
+// update replaces the energy of all Ohmpilots with the given values keyed by Ohmpilot ID.
+func (c *ohmpilotEnergyCollector) update(energy map[string]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ohmpilots = energy
+}
+
+func collectMetricsFromTarget(ctx context.Context, client *fronius.SymoClient) {
 	start := time.Now()
 	log.WithFields(log.Fields{
 		"url":              client.Options.URL,
@@ -161,126 +485,560 @@ func collectMetricsFromTarget(client *fronius.SymoClient) {
 		"archiveEnabled":   client.Options.ArchiveEnabled,
 		"inverterRealtime": client.Options.InverterRealtimeEnabled,
 		"meterRealtime":    client.Options.MeterRealtimeEnabled,
+		"deviceInfo":       client.Options.DeviceInfoEnabled,
+		"inverterInfo":     client.Options.InverterInfoEnabled,
+		"storageRealtime":  client.Options.StorageRealtimeEnabled,
+		"ohmpilotRealtime": client.Options.OhmpilotRealtimeEnabled,
+		"sensorRealtime":   client.Options.SensorRealtimeEnabled,
 	}).Debug("Requesting data.")
 
 	wg := sync.WaitGroup{}
-	wg.Add(4)
+	wg.Add(6)
+
+	go collectPowerFlowData(ctx, client, &wg)
+	go collectArchiveData(ctx, client, &wg)
+	go collectMeterRealtimeData(ctx, client, &wg)
+	go collectInverterInfo(ctx, client, &wg)
+	go collectStorageRealtimeData(ctx, client, &wg)
+	go collectOhmpilotRealtimeData(ctx, client, &wg)
 
-	collectPowerFlowData(client, &wg)
-	collectArchiveData(client, &wg)
-	collectInverterRealtimeData(client, &wg)
-	collectMeterRealtimeData(client, &wg)
+	// The active devices are requested once for all collectors that need them, to spare the Datamanager.
+	deviceInfo := getActiveDeviceInfo(ctx, client)
+	if deviceInfo != nil && client.Options.DeviceInfoEnabled {
+		parseDeviceInfo(deviceInfo)
+	}
+	wg.Add(2)
+	go collectInverterRealtimeData(ctx, client, deviceInfo, &wg)
+	go collectSensorRealtimeData(ctx, client, deviceInfo, &wg)
 
 	wg.Wait()
 	elapsed := time.Since(start)
 	scrapeDurationGauge.Set(elapsed.Seconds())
 }
 
-func collectPowerFlowData(client *fronius.SymoClient, w *sync.WaitGroup) {
+// handleScrapeError logs and counts an error that occurred while collecting the given endpoint.
+// Errors caused by an expired scrape deadline or by unexpected HTTP responses are additionally counted per endpoint.
+func handleScrapeError(ctx context.Context, endpoint string, err error, fields log.Fields, msg string) {
+	entry := log.WithError(err).WithFields(fields)
+	scrapeErrorCount.Add(1)
+	if ctx.Err() != nil {
+		entry.WithField("endpoint", endpoint).Warn("Scrape deadline exceeded, endpoint has been cut off.")
+		scrapeTimeoutCountVec.WithLabelValues(endpoint).Inc()
+		return
+	}
+	var httpErr *fronius.HTTPError
+	if errors.As(err, &httpErr) {
+		entry.WithField("statusCode", httpErr.StatusCode).Warn(msg)
+		scrapeHTTPErrorCountVec.WithLabelValues(endpoint, strconv.Itoa(httpErr.StatusCode)).Inc()
+		return
+	}
+	entry.Warn(msg)
+}
+
+// retryEndpoints maps the file names of the Solar API paths to the endpoint labels used by the scrape error metrics.
+var retryEndpoints = map[string]string{
+	"GetPowerFlowRealtimeData.fcgi": "power_flow",
+	"GetActiveDeviceInfo.cgi":       "device_info",
+	"GetInverterRealtimeData.cgi":   "inverter_realtime",
+	"GetMeterRealtimeData.cgi":      "meter_realtime",
+	"GetArchiveData.cgi":            "archive",
+	"GetInverterInfo.cgi":           "inverter_info",
+	"GetStorageRealtimeData.cgi":    "storage_realtime",
+	"GetOhmPilotRealtimeData.cgi":   "ohmpilot_realtime",
+	"GetSensorRealtimeData.cgi":     "sensor_realtime",
+}
+
+// countRetry logs and counts a retry of the given Solar API path.
+// The endpoint label is the same as for the scrape error metrics, e.g. inverter_realtime.
+// Unknown paths are labelled with their file name.
+func countRetry(apiPath string, attempt int, err error) {
+	endpoint := path.Base(strings.SplitN(apiPath, "?", 2)[0])
+	if name, found := retryEndpoints[endpoint]; found {
+		endpoint = name
+	}
+	log.WithError(err).WithFields(log.Fields{
+		"endpoint": endpoint,
+		"attempt":  attempt,
+	}).Debug("Retrying failed request.")
+	scrapeRetryCountVec.WithLabelValues(endpoint).Inc()
+}
+
+func collectPowerFlowData(ctx context.Context, client *fronius.SymoClient, w *sync.WaitGroup) {
 	defer w.Done()
 	if client.Options.PowerFlowEnabled {
-		powerFlowData, err := client.GetPowerFlowData()
+		powerFlowData, err := client.GetPowerFlowDataWithContext(ctx)
 		if err != nil {
-			log.WithError(err).Warn("Could not collect Symo power metrics.")
-			scrapeErrorCount.Add(1)
+			handleScrapeError(ctx, "power_flow", err, nil, "Could not collect Symo power metrics.")
 			return
 		}
 		parsePowerFlowMetrics(powerFlowData)
 	}
 }
 
-func collectInverterRealtimeData(client *fronius.SymoClient, w *sync.WaitGroup) {
+// getActiveDeviceInfo returns the devices attached to the Datamanager if any of the enabled collectors needs them.
+// It returns nil if none does or the request failed.
+func getActiveDeviceInfo(ctx context.Context, client *fronius.SymoClient) *fronius.ActiveDeviceInfo {
+	if !client.Options.DeviceInfoEnabled && !client.Options.InverterRealtimeEnabled && !client.Options.SensorRealtimeEnabled {
+		return nil
+	}
+	deviceInfo, err := client.GetActiveDeviceInfoWithContext(ctx)
+	if err != nil {
+		handleScrapeError(ctx, "device_info", err, nil, "Could not collect Symo device info.")
+		return nil
+	}
+	return deviceInfo
+}
+
+// collectInverterRealtimeData collects the enabled data collections of the active inverters.
+// Nothing is collected if deviceInfo is nil, since the inverters can't be discovered then.
+func collectInverterRealtimeData(ctx context.Context, client *fronius.SymoClient, deviceInfo *fronius.ActiveDeviceInfo, w *sync.WaitGroup) {
 	defer w.Done()
-	if client.Options.InverterRealtimeEnabled {
-		powerFlowData, err := client.GetInverterRealtimeData()
+	if client.Options.InverterRealtimeEnabled && deviceInfo != nil {
+		resetInverterRealtimeMetrics()
+		for _, inverterID := range deviceInfo.InverterIDs() {
+			for _, collection := range client.Options.InverterDataCollections {
+				if err := collectInverterDataCollection(ctx, client, inverterID, collection); err != nil {
+					handleScrapeError(ctx, "inverter_realtime", err, log.Fields{
+						"inverter":       inverterID,
+						"dataCollection": collection,
+					}, "Could not collect Symo inverter realtime metrics.")
+					if ctx.Err() != nil {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// resetInverterRealtimeMetrics deletes the metrics of all inverter data collections,
+// so that inverters that are no longer active are no longer exported.
+func resetInverterRealtimeMetrics() {
+	for _, vec := range []*prometheus.GaugeVec{
+		siteRealtimeDataDcCurrentMPPT1GaugeVec,
+		siteRealtimeDataDcCurrentMPPT2GaugeVec,
+		siteRealtimeDataDcCurrentMPPT3GaugeVec,
+		siteRealtimeDataDcCurrentMPPT4GaugeVec,
+		siteRealtimeDataDcVoltageMPPT1GaugeVec,
+		siteRealtimeDataDcVoltageMPPT2GaugeVec,
+		siteRealtimeDataDcVoltageMPPT3GaugeVec,
+		siteRealtimeDataDcVoltageMPPT4GaugeVec,
+		siteRealtimeDataAcFrequencyGaugeVec,
+		siteRealtimeDataAcPowerGaugeVec,
+		siteRealtimeDataTotalEnergyGeneratedGaugeVec,
+		inverterAcCurrentGaugeVec,
+		inverterAcVoltageGaugeVec,
+		inverterPowerExtremeGaugeVec,
+		inverterAcVoltageExtremeGaugeVec,
+		inverterDcVoltageExtremeGaugeVec,
+	} {
+		vec.Reset()
+	}
+}
+
+func collectInverterDataCollection(ctx context.Context, client *fronius.SymoClient, inverterID, collection string) error {
+	switch collection {
+	case fronius.CommonInverterDataCollection:
+		inverterData, err := client.GetInverterRealtimeDataWithContext(ctx, inverterID)
 		if err != nil {
-			log.WithError(err).Warn("Could not collect Symo inverter realtime metrics.")
-			scrapeErrorCount.Add(1)
-			return
+			return err
+		}
+		parseInverterRealtimeData(inverterID, inverterData)
+	case fronius.ThreePhaseInverterDataCollection:
+		inverterData, err := client.GetInverter3PDataWithContext(ctx, inverterID)
+		if err != nil {
+			return err
+		}
+		parseInverter3PData(inverterID, inverterData)
+	case fronius.MinMaxInverterDataCollection:
+		inverterData, err := client.GetInverterMinMaxDataWithContext(ctx, inverterID)
+		if err != nil {
+			return err
 		}
-		parseInverterRealtimeData(powerFlowData)
+		parseInverterMinMaxData(inverterID, inverterData)
 	}
+	return nil
 }
 
-func collectMeterRealtimeData(client *fronius.SymoClient, w *sync.WaitGroup) {
+func collectMeterRealtimeData(ctx context.Context, client *fronius.SymoClient, w *sync.WaitGroup) {
 	defer w.Done()
 	if client.Options.MeterRealtimeEnabled {
-		meterData, err := client.GetMeterRealtimeData()
+		meterData, err := client.GetMeterRealtimeDataWithContext(ctx)
 		if err != nil {
-			log.WithError(err).Warn("Could not collect Symo meter realtime metrics.")
-			scrapeErrorCount.Add(1)
+			handleScrapeError(ctx, "meter_realtime", err, nil, "Could not collect Symo meter realtime metrics.")
 			return
 		}
-		parseMeterRealtimeData(meterData)
+		resetMeterMetrics()
+		for meterID, meter := range meterData {
+			parseMeterRealtimeData(meterID, &meter)
+		}
 	}
 }
 
-func collectArchiveData(client *fronius.SymoClient, w *sync.WaitGroup) {
+func collectArchiveData(ctx context.Context, client *fronius.SymoClient, w *sync.WaitGroup) {
 	defer w.Done()
 	if client.Options.ArchiveEnabled {
-		archiveData, err := client.GetArchiveData()
+		archiveData, err := client.GetArchiveDataWithContext(ctx)
 		if err != nil {
-			log.WithError(err).Warn("Could not collect Symo archive metrics.")
-			scrapeErrorCount.Add(1)
+			handleScrapeError(ctx, "archive", err, nil, "Could not collect Symo archive metrics.")
 			return
 		}
 		parseArchiveMetrics(archiveData)
 	}
 }
 
+func collectInverterInfo(ctx context.Context, client *fronius.SymoClient, w *sync.WaitGroup) {
+	defer w.Done()
+	if client.Options.InverterInfoEnabled {
+		inverterInfo, err := client.GetInverterInfoWithContext(ctx)
+		if err != nil {
+			handleScrapeError(ctx, "inverter_info", err, nil, "Could not collect Symo inverter info metrics.")
+			return
+		}
+		parseInverterInfo(inverterInfo)
+	}
+}
+
+func collectStorageRealtimeData(ctx context.Context, client *fronius.SymoClient, w *sync.WaitGroup) {
+	defer w.Done()
+	if client.Options.StorageRealtimeEnabled {
+		storageData, err := client.GetStorageRealtimeDataWithContext(ctx)
+		if err != nil {
+			handleScrapeError(ctx, "storage_realtime", err, nil, "Could not collect Symo storage realtime metrics.")
+			return
+		}
+		parseStorageRealtimeData(storageData)
+	}
+}
+
+func collectOhmpilotRealtimeData(ctx context.Context, client *fronius.SymoClient, w *sync.WaitGroup) {
+	defer w.Done()
+	if client.Options.OhmpilotRealtimeEnabled {
+		ohmpilotData, err := client.GetOhmpilotRealtimeDataWithContext(ctx)
+		if err != nil {
+			handleScrapeError(ctx, "ohmpilot_realtime", err, nil, "Could not collect Symo Ohmpilot realtime metrics.")
+			return
+		}
+		parseOhmpilotRealtimeData(ohmpilotData)
+	}
+}
+
+func collectSensorRealtimeData(ctx context.Context, client *fronius.SymoClient, deviceInfo *fronius.ActiveDeviceInfo, w *sync.WaitGroup) {
+	defer w.Done()
+	if client.Options.SensorRealtimeEnabled {
+		// The channel names are only known if the active devices could be requested.
+		channelNames := map[string][]string{}
+		if deviceInfo != nil {
+			for key, sensorCard := range deviceInfo.SensorCards {
+				channelNames[key] = sensorCard.ChannelNames
+			}
+		}
+
+		sensorData, err := client.GetSensorRealtimeDataWithContext(ctx)
+		if err != nil {
+			handleScrapeError(ctx, "sensor_realtime", err, nil, "Could not collect Symo sensor realtime metrics.")
+			return
+		}
+		parseSensorRealtimeData(sensorData, channelNames)
+
+		minMaxData, err := client.GetSensorMinMaxDataWithContext(ctx)
+		if err != nil {
+			handleScrapeError(ctx, "sensor_realtime", err, nil, "Could not collect Symo sensor min/max metrics.")
+			return
+		}
+		parseSensorMinMaxData(minMaxData, channelNames)
+	}
+}
+
 func parsePowerFlowMetrics(data *fronius.SymoData) {
 	log.WithField("powerFlowData", *data).Debug("Parsing data.")
 	for key, inverter := range data.Inverters {
-		inverterPowerGaugeVec.WithLabelValues(key).Set(inverter.Power)
-		inverterBatteryChargeGaugeVec.WithLabelValues(key).Set(inverter.BatterySoC / 100)
+		setOptional(inverterPowerGaugeVec, inverter.Power, key)
+		setOptional(inverterBatteryChargeGaugeVec, percentToRatio(inverter.BatterySoC), key)
 	}
-	sitePowerAccuGauge.Set(data.Site.PowerAccu)
-	sitePowerGridGauge.Set(data.Site.PowerGrid)
-	sitePowerLoadGauge.Set(data.Site.PowerLoad)
-	sitePowerPhotovoltaicsGauge.Set(data.Site.PowerPhotovoltaic)
+	setOptional(sitePowerAccuGaugeVec, data.Site.PowerAccu)
+	setOptional(sitePowerGridGaugeVec, data.Site.PowerGrid)
+	setOptional(sitePowerLoadGaugeVec, data.Site.PowerLoad)
+	setOptional(sitePowerPhotovoltaicsGaugeVec, data.Site.PowerPhotovoltaic)
 
-	siteEnergyGaugeVec.WithLabelValues("day").Set(data.Site.EnergyDay)
-	siteEnergyGaugeVec.WithLabelValues("year").Set(data.Site.EnergyYear)
-	siteEnergyGaugeVec.WithLabelValues("total").Set(data.Site.EnergyTotal)
+	setOptional(siteEnergyGaugeVec, data.Site.EnergyDay, "day")
+	setOptional(siteEnergyGaugeVec, data.Site.EnergyYear, "year")
+	setOptional(siteEnergyGaugeVec, data.Site.EnergyTotal, "total")
 
-	siteAutonomyRatioGauge.Set(data.Site.RelativeAutonomy / 100)
-	if data.Site.PowerPhotovoltaic == 0 {
-		siteSelfConsumptionRatioGauge.Set(1)
-	} else {
-		siteSelfConsumptionRatioGauge.Set(data.Site.RelativeSelfConsumption / 100)
-	}
+	setOptional(siteAutonomyRatioGaugeVec, percentToRatio(data.Site.RelativeAutonomy))
+	setOptional(siteSelfConsumptionRatioGaugeVec, percentToRatio(data.Site.RelativeSelfConsumption))
 }
 
-func parseInverterRealtimeData(data *fronius.SymoInverterRealtimeData) {
-	log.WithField("InverterRealtimeData", *data).Debug("Parsing data.")
-	siteRealtimeDataDcCurrentMPPT1Gauge.Set(data.DcCurrentMPPT1.Value)
-	siteRealtimeDataDcCurrentMPPT2Gauge.Set(data.DcCurrentMPPT2.Value)
-	siteRealtimeDataDcCurrentMPPT3Gauge.Set(data.DcCurrentMPPT3.Value)
-	siteRealtimeDataDcCurrentMPPT4Gauge.Set(data.DcCurrentMPPT4.Value)
+func parseInverterRealtimeData(inverterID string, data *fronius.SymoInverterRealtimeData) {
+	log.WithFields(log.Fields{
+		"inverter":             inverterID,
+		"InverterRealtimeData": *data,
+	}).Debug("Parsing data.")
+	setOptional(siteRealtimeDataDcCurrentMPPT1GaugeVec, data.DcCurrentMPPT1.Value, inverterID)
+	setOptional(siteRealtimeDataDcCurrentMPPT2GaugeVec, data.DcCurrentMPPT2.Value, inverterID)
+	setOptional(siteRealtimeDataDcCurrentMPPT3GaugeVec, data.DcCurrentMPPT3.Value, inverterID)
+	setOptional(siteRealtimeDataDcCurrentMPPT4GaugeVec, data.DcCurrentMPPT4.Value, inverterID)
 
-	siteRealtimeDataDcVoltageMPPT1Gauge.Set(data.DcVoltageMPPT1.Value)
-	siteRealtimeDataDcVoltageMPPT2Gauge.Set(data.DcVoltageMPPT2.Value)
-	siteRealtimeDataDcVoltageMPPT3Gauge.Set(data.DcVoltageMPPT3.Value)
-	siteRealtimeDataDcVoltageMPPT4Gauge.Set(data.DcVoltageMPPT4.Value)
+	setOptional(siteRealtimeDataDcVoltageMPPT1GaugeVec, data.DcVoltageMPPT1.Value, inverterID)
+	setOptional(siteRealtimeDataDcVoltageMPPT2GaugeVec, data.DcVoltageMPPT2.Value, inverterID)
+	setOptional(siteRealtimeDataDcVoltageMPPT3GaugeVec, data.DcVoltageMPPT3.Value, inverterID)
+	setOptional(siteRealtimeDataDcVoltageMPPT4GaugeVec, data.DcVoltageMPPT4.Value, inverterID)
 
-	siteRealtimeDataAcFrequencyGauge.Set(data.AcFrequency.Value)
-	siteRealtimeDataAcPowerGauge.Set(data.AcPower.Value)
-	siteRealtimeDataTotalEnergyGeneratedGauge.Set(data.TotalEnergyGenerated.Value)
+	setOptional(siteRealtimeDataAcFrequencyGaugeVec, data.AcFrequency.Value, inverterID)
+	setOptional(siteRealtimeDataAcPowerGaugeVec, data.AcPower.Value, inverterID)
+	setOptional(siteRealtimeDataTotalEnergyGeneratedGaugeVec, data.TotalEnergyGenerated.Value, inverterID)
 }
 
-func parseMeterRealtimeData(data *fronius.SymoMeterRealtimeData) {
-	log.WithField("MeterRealtimeData", *data).Debug("Parsing data.")
-	siteMeterRealTimeDataEnergyReal_WAC_Sum_Consumed.Set(data.EnergyReal_WAC_Sum_Consumed)
-	siteMeterRealTimeDataEnergyReal_WAC_Sum_Produced.Set(data.EnergyReal_WAC_Sum_Produced)
+func parseInverter3PData(inverterID string, data *fronius.SymoInverter3PData) {
+	log.WithFields(log.Fields{
+		"inverter":       inverterID,
+		"Inverter3PData": *data,
+	}).Debug("Parsing data.")
+	setOptional(inverterAcCurrentGaugeVec, data.AcCurrentL1.Value, inverterID, "1")
+	setOptional(inverterAcCurrentGaugeVec, data.AcCurrentL2.Value, inverterID, "2")
+	setOptional(inverterAcCurrentGaugeVec, data.AcCurrentL3.Value, inverterID, "3")
+
+	setOptional(inverterAcVoltageGaugeVec, data.AcVoltageL1.Value, inverterID, "1")
+	setOptional(inverterAcVoltageGaugeVec, data.AcVoltageL2.Value, inverterID, "2")
+	setOptional(inverterAcVoltageGaugeVec, data.AcVoltageL3.Value, inverterID, "3")
+}
+
+func parseInverterMinMaxData(inverterID string, data *fronius.SymoInverterMinMaxData) {
+	log.WithFields(log.Fields{
+		"inverter":           inverterID,
+		"InverterMinMaxData": *data,
+	}).Debug("Parsing data.")
+	setOptional(inverterPowerExtremeGaugeVec, data.DayPowerMax.Value, inverterID, "day", "max")
+	setOptional(inverterPowerExtremeGaugeVec, data.YearPowerMax.Value, inverterID, "year", "max")
+	setOptional(inverterPowerExtremeGaugeVec, data.TotalPowerMax.Value, inverterID, "total", "max")
+
+	setOptional(inverterAcVoltageExtremeGaugeVec, data.DayAcVoltageMax.Value, inverterID, "day", "max")
+	setOptional(inverterAcVoltageExtremeGaugeVec, data.YearAcVoltageMax.Value, inverterID, "year", "max")
+	setOptional(inverterAcVoltageExtremeGaugeVec, data.TotalAcVoltageMax.Value, inverterID, "total", "max")
+	setOptional(inverterAcVoltageExtremeGaugeVec, data.DayAcVoltageMin.Value, inverterID, "day", "min")
+	setOptional(inverterAcVoltageExtremeGaugeVec, data.YearAcVoltageMin.Value, inverterID, "year", "min")
+	setOptional(inverterAcVoltageExtremeGaugeVec, data.TotalAcVoltageMin.Value, inverterID, "total", "min")
+
+	setOptional(inverterDcVoltageExtremeGaugeVec, data.DayDcVoltageMax.Value, inverterID, "day", "max")
+	setOptional(inverterDcVoltageExtremeGaugeVec, data.YearDcVoltageMax.Value, inverterID, "year", "max")
+	setOptional(inverterDcVoltageExtremeGaugeVec, data.TotalDcVoltageMax.Value, inverterID, "total", "max")
+}
+
+// setOptional sets the gauge with the given label values to value, or deletes it if the device didn't report the value.
+func setOptional(vec *prometheus.GaugeVec, value *float64, labelValues ...string) {
+	if value == nil {
+		vec.DeleteLabelValues(labelValues...)
+		return
+	}
+	vec.WithLabelValues(labelValues...).Set(*value)
+}
+
+// percentToRatio converts a percentage between 0 and 100 to a ratio between 0 and 1.
+func percentToRatio(percent *float64) *float64 {
+	if percent == nil {
+		return nil
+	}
+	ratio := *percent / 100
+	return &ratio
+}
+
+// resetMeterMetrics deletes the metrics of all meters,
+// so that meters that have been removed or moved to another location are no longer exported.
+func resetMeterMetrics() {
+	for _, vec := range []*prometheus.GaugeVec{
+		siteMeterRealTimeDataEnergyReal_WAC_Sum_Consumed,
+		siteMeterRealTimeDataEnergyReal_WAC_Sum_Produced,
+		siteMeterVoltageGaugeVec,
+		siteMeterCurrentGaugeVec,
+		siteMeterPowerRealGaugeVec,
+		siteMeterPowerReactiveGaugeVec,
+		siteMeterPowerApparentGaugeVec,
+		siteMeterPowerFactorGaugeVec,
+		siteMeterFrequencyGaugeVec,
+	} {
+		vec.Reset()
+	}
+	siteMeterEnergyRegisters.reset()
+}
+
+func parseMeterRealtimeData(meterID string, data *fronius.SymoMeterRealtimeData) {
+	log.WithFields(log.Fields{
+		"meter":             meterID,
+		"MeterRealtimeData": *data,
+	}).Debug("Parsing data.")
+	location := data.Location()
+	setOptional(siteMeterRealTimeDataEnergyReal_WAC_Sum_Consumed, data.EnergyReal_WAC_Sum_Consumed, meterID, location)
+	setOptional(siteMeterRealTimeDataEnergyReal_WAC_Sum_Produced, data.EnergyReal_WAC_Sum_Produced, meterID, location)
+
+	phases := []struct {
+		phase                                                   string
+		voltage, current, real, reactive, apparent, powerFactor *float64
+	}{
+		{"1", data.Voltage_AC_Phase_1, data.Current_AC_Phase_1, data.PowerReal_P_Phase_1, data.PowerReactive_Q_Phase_1, data.PowerApparent_S_Phase_1, data.PowerFactor_Phase_1},
+		{"2", data.Voltage_AC_Phase_2, data.Current_AC_Phase_2, data.PowerReal_P_Phase_2, data.PowerReactive_Q_Phase_2, data.PowerApparent_S_Phase_2, data.PowerFactor_Phase_2},
+		{"3", data.Voltage_AC_Phase_3, data.Current_AC_Phase_3, data.PowerReal_P_Phase_3, data.PowerReactive_Q_Phase_3, data.PowerApparent_S_Phase_3, data.PowerFactor_Phase_3},
+	}
+	for _, p := range phases {
+		setOptional(siteMeterVoltageGaugeVec, p.voltage, meterID, location, p.phase)
+		setOptional(siteMeterCurrentGaugeVec, p.current, meterID, location, p.phase)
+		setOptional(siteMeterPowerRealGaugeVec, p.real, meterID, location, p.phase)
+		setOptional(siteMeterPowerReactiveGaugeVec, p.reactive, meterID, location, p.phase)
+		setOptional(siteMeterPowerApparentGaugeVec, p.apparent, meterID, location, p.phase)
+		setOptional(siteMeterPowerFactorGaugeVec, p.powerFactor, meterID, location, p.phase)
+	}
+	setOptional(siteMeterFrequencyGaugeVec, data.Frequency_Phase_Average, meterID, location)
+
+	siteMeterEnergyRegisters.update(meterID, meterEnergy{
+		location:         location,
+		realConsumed:     [3]*float64{data.EnergyReal_WAC_Phase_1_Consumed, data.EnergyReal_WAC_Phase_2_Consumed, data.EnergyReal_WAC_Phase_3_Consumed},
+		realProduced:     [3]*float64{data.EnergyReal_WAC_Phase_1_Produced, data.EnergyReal_WAC_Phase_2_Produced, data.EnergyReal_WAC_Phase_3_Produced},
+		reactiveConsumed: data.EnergyReactive_VArAC_Sum_Consumed,
+		reactiveProduced: data.EnergyReactive_VArAC_Sum_Produced,
+	})
 }
 
 func parseArchiveMetrics(data map[string]fronius.InverterArchive) {
 	log.WithField("archiveData", data).Debug("Parsing data.")
+	archiveChannelGaugeVec.Reset()
+	siteMPPTCurrentDCGaugeVec.Reset()
+	siteMPPTVoltageGaugeVec.Reset()
 	for key, inverter := range data {
 		key = strings.TrimPrefix(key, "inverter/")
-		siteMPPTCurrentDCGaugeVec.WithLabelValues(key, "1").Set(inverter.Data.CurrentDCString1.Values["0"])
-		siteMPPTCurrentDCGaugeVec.WithLabelValues(key, "2").Set(inverter.Data.CurrentDCString2.Values["0"])
-		siteMPPTVoltageGaugeVec.WithLabelValues(key, "1").Set(inverter.Data.VoltageDCString1.Values["0"])
-		siteMPPTVoltageGaugeVec.WithLabelValues(key, "2").Set(inverter.Data.VoltageDCString2.Values["0"])
+		for name, channel := range inverter.Data {
+			value, found := channel.Latest()
+			if !found {
+				continue
+			}
+			archiveChannelGaugeVec.WithLabelValues(key, name, channel.Unit).Set(value)
+
+			// The DC string channels are also exposed with the MPPT metrics for backwards compatibility.
+			if mppt, ok := strings.CutPrefix(name, "Current_DC_String_"); ok {
+				siteMPPTCurrentDCGaugeVec.WithLabelValues(key, mppt).Set(value)
+			} else if mppt, ok := strings.CutPrefix(name, "Voltage_DC_String_"); ok {
+				siteMPPTVoltageGaugeVec.WithLabelValues(key, mppt).Set(value)
+			}
+		}
+	}
+}
+
+func parseDeviceInfo(data *fronius.ActiveDeviceInfo) {
+	log.WithField("deviceInfo", *data).Debug("Parsing data.")
+	deviceInfoGaugeVec.Reset()
+	for class, devices := range map[string]map[string]fronius.ActiveDevice{
+		"inverter":      data.Inverters,
+		"meter":         data.Meters,
+		"storage":       data.Storages,
+		"ohmpilot":      data.Ohmpilots,
+		"sensorcard":    data.SensorCards,
+		"stringcontrol": data.StringControls,
+	} {
+		for id, device := range devices {
+			deviceType := strconv.FormatFloat(device.DT, 'f', -1, 64)
+			deviceInfoGaugeVec.WithLabelValues(class, id, deviceType, device.Serial).Set(1)
+		}
+	}
+}
+
+func parseInverterInfo(data map[string]fronius.InverterInfo) {
+	log.WithField("inverterInfo", data).Debug("Parsing data.")
+	inverterInfoGaugeVec.Reset()
+	inverterStatusCodeGaugeVec.Reset()
+	inverterErrorCodeGaugeVec.Reset()
+	inverterPVPowerGaugeVec.Reset()
+	for key, inverter := range data {
+		inverterInfoGaugeVec.WithLabelValues(key, inverter.CustomName, inverter.UniqueID).Set(1)
+		inverterStatusCodeGaugeVec.WithLabelValues(key, inverter.State()).Set(inverter.StatusCode)
+		inverterErrorCodeGaugeVec.WithLabelValues(key).Set(inverter.ErrorCode)
+		inverterPVPowerGaugeVec.WithLabelValues(key).Set(inverter.PVPower)
+	}
+}
+
+func parseStorageRealtimeData(data map[string]fronius.StorageRealtimeData) {
+	log.WithField("storageRealtimeData", data).Debug("Parsing data.")
+	storageInfoGaugeVec.Reset()
+	storageStatusCodeGaugeVec.Reset()
+	storageChargeGaugeVec.Reset()
+	storageCapacityMaximumGaugeVec.Reset()
+	storageCapacityDesignedGaugeVec.Reset()
+	storageCycleCountGaugeVec.Reset()
+	storageCurrentDCGaugeVec.Reset()
+	storageVoltageDCGaugeVec.Reset()
+	storageCellTemperatureGaugeVec.Reset()
+	for key, storage := range data {
+		controller := storage.Controller
+		storageInfoGaugeVec.WithLabelValues(key, controller.Details.Manufacturer, controller.Details.Model, controller.Details.Serial).Set(1)
+		setOptional(storageStatusCodeGaugeVec, controller.StatusBatteryCell, key)
+		setOptional(storageChargeGaugeVec, percentToRatio(controller.StateOfCharge), key)
+		setOptional(storageCapacityMaximumGaugeVec, controller.CapacityMaximum, key)
+		setOptional(storageCapacityDesignedGaugeVec, controller.DesignedCapacity, key)
+		setOptional(storageCycleCountGaugeVec, controller.CycleCount, key)
+		setOptional(storageCurrentDCGaugeVec, controller.CurrentDC, key)
+		setOptional(storageVoltageDCGaugeVec, controller.VoltageDC, key)
+		setOptional(storageCellTemperatureGaugeVec, controller.TemperatureCell, key, "average")
+		setOptional(storageCellTemperatureGaugeVec, controller.TemperatureCellMaximum, key, "max")
+		setOptional(storageCellTemperatureGaugeVec, controller.TemperatureCellMinimum, key, "min")
+	}
+}
+
+func parseOhmpilotRealtimeData(data map[string]fronius.OhmpilotRealtimeData) {
+	log.WithField("ohmpilotRealtimeData", data).Debug("Parsing data.")
+	ohmpilotInfoGaugeVec.Reset()
+	ohmpilotStateCodeGaugeVec.Reset()
+	ohmpilotErrorCodeGaugeVec.Reset()
+	ohmpilotPowerGaugeVec.Reset()
+	ohmpilotTemperatureGaugeVec.Reset()
+	energy := map[string]float64{}
+	for key, ohmpilot := range data {
+		ohmpilotInfoGaugeVec.WithLabelValues(key, ohmpilot.Details.Model, ohmpilot.Details.Serial).Set(1)
+		ohmpilotStateCodeGaugeVec.WithLabelValues(key, ohmpilot.State()).Set(ohmpilot.CodeOfState)
+		setOptional(ohmpilotErrorCodeGaugeVec, ohmpilot.CodeOfError, key)
+		setOptional(ohmpilotPowerGaugeVec, ohmpilot.Power, key)
+		setOptional(ohmpilotTemperatureGaugeVec, ohmpilot.Temperature, key)
+		if ohmpilot.EnergyConsumed != nil {
+			energy[key] = *ohmpilot.EnergyConsumed
+		}
+	}
+	ohmpilotEnergyConsumed.update(energy)
+}
+
+func parseSensorRealtimeData(data map[string]map[string]fronius.SensorChannel, channelNames map[string][]string) {
+	log.WithField("sensorRealtimeData", data).Debug("Parsing data.")
+	sensorChannelGaugeVec.Reset()
+	for card, channels := range data {
+		for channel, value := range channels {
+			name := sensorChannelName(channelNames[card], channel)
+			setOptional(sensorChannelGaugeVec, value.Value, card, channel, name, value.Unit)
+		}
+	}
+}
+
+func parseSensorMinMaxData(data map[string]map[string]fronius.SensorChannelMinMax, channelNames map[string][]string) {
+	log.WithField("sensorMinMaxData", data).Debug("Parsing data.")
+	sensorChannelExtremeGaugeVec.Reset()
+	for card, channels := range data {
+		for channel, value := range channels {
+			name := sensorChannelName(channelNames[card], channel)
+			extremes := []struct {
+				period, kind string
+				value        *float64
+			}{
+				{"day", "min", value.DayMin}, {"day", "max", value.DayMax},
+				{"month", "min", value.MonthMin}, {"month", "max", value.MonthMax},
+				{"year", "min", value.YearMin}, {"year", "max", value.YearMax},
+				{"total", "min", value.TotalMin}, {"total", "max", value.TotalMax},
+			}
+			for _, e := range extremes {
+				setOptional(sensorChannelExtremeGaugeVec, e.value, card, channel, name, value.Unit, e.period, e.kind)
+			}
+		}
+	}
+}
+
+// sensorChannelName returns the name of the given channel ID, or an empty string if the name is unknown.
+func sensorChannelName(names []string, channel string) string {
+	index, err := strconv.Atoi(channel)
+	if err != nil || index < 0 || index >= len(names) {
+		return ""
 	}
+	return names[index]
 }
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ccremer/fronius-exporter/pkg/fronius"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_handleScrapeError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := map[string]struct {
+		ctx              context.Context
+		err              error
+		expectedHTTP     float64
+		expectedTimeouts float64
+	}{
+		"GivenHTTPError_ThenCountErrorAndStatusCode": {
+			ctx:          context.Background(),
+			err:          &fronius.HTTPError{StatusCode: 503},
+			expectedHTTP: 1,
+		},
+		"GivenExpiredDeadline_ThenCountErrorAndTimeout": {
+			ctx:              cancelled,
+			err:              context.Canceled,
+			expectedTimeouts: 1,
+		},
+		"GivenOtherError_ThenCountError": {
+			ctx: context.Background(),
+			err: errors.New("connection refused"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			scrapeErrors := testutil.ToFloat64(scrapeErrorCount)
+			httpErrors := testutil.ToFloat64(scrapeHTTPErrorCountVec.WithLabelValues("test", "503"))
+			timeouts := testutil.ToFloat64(scrapeTimeoutCountVec.WithLabelValues("test"))
+
+			handleScrapeError(tt.ctx, "test", tt.err, nil, "Could not collect test metrics.")
+
+			assert.Equal(t, scrapeErrors+1, testutil.ToFloat64(scrapeErrorCount), "every error should be counted")
+			assert.Equal(t, httpErrors+tt.expectedHTTP, testutil.ToFloat64(scrapeHTTPErrorCountVec.WithLabelValues("test", "503")))
+			assert.Equal(t, timeouts+tt.expectedTimeouts, testutil.ToFloat64(scrapeTimeoutCountVec.WithLabelValues("test")))
+		})
+	}
+}
+
+func Test_collectMetricsFromTarget_GivenCollectorsNeedingActiveDevices_ThenRequestThemOnce(t *testing.T) {
+	fixtures := map[string]string{
+		"/solar_api/v1/GetActiveDeviceInfo.cgi":     "pkg/fronius/testdata/activedeviceinfo.json",
+		"/solar_api/v1/GetInverterRealtimeData.cgi": "pkg/fronius/testdata/realtimedata.json",
+		"/solar_api/v1/GetSensorRealtimeData.cgi":   "pkg/fronius/testdata/sensorrealtimedata.json",
+	}
+	mu := sync.Mutex{}
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requests[req.URL.Path]++
+		mu.Unlock()
+		fixture := fixtures[req.URL.Path]
+		if req.URL.Query().Get("DataCollection") == "MinMaxSensorData" {
+			fixture = "pkg/fronius/testdata/minmaxsensordata.json"
+		}
+		payload, err := os.ReadFile(fixture)
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+	defer server.Close()
+
+	client, err := fronius.NewSymoClient(fronius.ClientOptions{
+		URL:                     server.URL,
+		DeviceInfoEnabled:       true,
+		InverterRealtimeEnabled: true,
+		InverterDataCollections: []string{fronius.CommonInverterDataCollection},
+		SensorRealtimeEnabled:   true,
+	})
+	require.NoError(t, err)
+
+	collectMetricsFromTarget(context.Background(), client)
+
+	assert.Equal(t, 1, requests["/solar_api/v1/GetActiveDeviceInfo.cgi"])
+	assert.Equal(t, 2, requests["/solar_api/v1/GetInverterRealtimeData.cgi"], "both inverters should be discovered")
+	assert.Equal(t, 2, requests["/solar_api/v1/GetSensorRealtimeData.cgi"])
+	assert.Equal(t, 1.0, testutil.ToFloat64(deviceInfoGaugeVec.WithLabelValues("inverter", "1", "123", "28136344")))
+}
+
+func Test_parseInverterInfo_GivenInverterNoLongerReported_ThenDeleteItsMetrics(t *testing.T) {
+	parseInverterInfo(map[string]fronius.InverterInfo{
+		"1": {CustomName: "Symo East", ErrorCode: 0, PVPower: 5000, StatusCode: 7, UniqueID: "100"},
+		"2": {CustomName: "Symo West", ErrorCode: 567, PVPower: 4000, StatusCode: 10, UniqueID: "200"},
+	})
+	assert.Equal(t, 2, testutil.CollectAndCount(inverterErrorCodeGaugeVec))
+	assert.Equal(t, 2, testutil.CollectAndCount(inverterPVPowerGaugeVec))
+
+	parseInverterInfo(map[string]fronius.InverterInfo{
+		"1": {CustomName: "Symo East", ErrorCode: 0, PVPower: 5000, StatusCode: 7, UniqueID: "100"},
+	})
+	for name, vec := range map[string]*prometheus.GaugeVec{
+		"info":        inverterInfoGaugeVec,
+		"status code": inverterStatusCodeGaugeVec,
+		"error code":  inverterErrorCodeGaugeVec,
+		"PV power":    inverterPVPowerGaugeVec,
+	} {
+		assert.Equal(t, 1, testutil.CollectAndCount(vec), name)
+	}
+	assert.Equal(t, 5000.0, testutil.ToFloat64(inverterPVPowerGaugeVec.WithLabelValues("1")))
+}
+
+func Test_collectMeterRealtimeData_GivenMeterMovedToOtherLocation_ThenDeleteOldMetrics(t *testing.T) {
+	location := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprintf(rw, `{"Body":{"Data":{"0":{"Meter_Location_Current":%d,"Voltage_AC_Phase_1":230,"EnergyReal_WAC_Phase_1_Consumed":1000}}},"Head":{"Status":{"Code":0}}}`, location)
+	}))
+	defer server.Close()
+	client, err := fronius.NewSymoClient(fronius.ClientOptions{URL: server.URL, MeterRealtimeEnabled: true})
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	collectMeterRealtimeData(context.Background(), client, &wg)
+	assert.Equal(t, 230.0, testutil.ToFloat64(siteMeterVoltageGaugeVec.WithLabelValues("0", "grid", "1")))
+
+	location = 1
+	wg.Add(1)
+	collectMeterRealtimeData(context.Background(), client, &wg)
+	assert.Equal(t, 1, testutil.CollectAndCount(siteMeterVoltageGaugeVec), "only the reported phase of the new location should be exported")
+	assert.Equal(t, 230.0, testutil.ToFloat64(siteMeterVoltageGaugeVec.WithLabelValues("0", "load", "1")))
+	assert.Equal(t, 1, testutil.CollectAndCount(siteMeterEnergyRegisters), "only the reported energy register of the new location should be exported")
+}
+
+func Test_parsePowerFlowMetrics_GivenNullValue_ThenDeleteSeries(t *testing.T) {
+	data := &fronius.SymoData{Inverters: map[string]fronius.Inverter{"1": {Power: floatPtr(2500)}}}
+	data.Site.PowerPhotovoltaic = floatPtr(2600)
+	data.Site.EnergyDay = floatPtr(12000)
+	parsePowerFlowMetrics(data)
+	assert.Equal(t, 2600.0, testutil.ToFloat64(sitePowerPhotovoltaicsGaugeVec.WithLabelValues()))
+	assert.Equal(t, 1, testutil.CollectAndCount(inverterPowerGaugeVec))
+
+	// At night, the device reports null for the PV power and the inverter power.
+	data = &fronius.SymoData{Inverters: map[string]fronius.Inverter{"1": {}}}
+	data.Site.EnergyDay = floatPtr(12000)
+	parsePowerFlowMetrics(data)
+	assert.Equal(t, 0, testutil.CollectAndCount(sitePowerPhotovoltaicsGaugeVec))
+	assert.Equal(t, 0, testutil.CollectAndCount(inverterPowerGaugeVec))
+	assert.Equal(t, 12000.0, testutil.ToFloat64(siteEnergyGaugeVec.WithLabelValues("day")))
+}
+
+func Test_parseStorageRealtimeData_GivenMissingValues_ThenDontExportThem(t *testing.T) {
+	storage := fronius.StorageRealtimeData{}
+	storage.Controller.StateOfCharge = floatPtr(50)
+	parseStorageRealtimeData(map[string]fronius.StorageRealtimeData{"0": storage})
+
+	assert.Equal(t, 0.5, testutil.ToFloat64(storageChargeGaugeVec.WithLabelValues("0")))
+	assert.Equal(t, 0, testutil.CollectAndCount(storageCellTemperatureGaugeVec))
+	assert.Equal(t, 0, testutil.CollectAndCount(storageCycleCountGaugeVec))
+}
+
+func Test_parseOhmpilotRealtimeData_GivenNoError_ThenDontExportErrorCode(t *testing.T) {
+	parseOhmpilotRealtimeData(map[string]fronius.OhmpilotRealtimeData{"0": {Power: floatPtr(1523.5)}})
+
+	assert.Equal(t, 1523.5, testutil.ToFloat64(ohmpilotPowerGaugeVec.WithLabelValues("0")))
+	assert.Equal(t, 0, testutil.CollectAndCount(ohmpilotErrorCodeGaugeVec))
+	assert.Equal(t, 0, testutil.CollectAndCount(ohmpilotTemperatureGaugeVec))
+}
+
+func Test_parseSensorData_GivenNullValues_ThenDontExportThem(t *testing.T) {
+	parseSensorRealtimeData(map[string]map[string]fronius.SensorChannel{
+		"1": {"0": {Unit: "°C", Value: floatPtr(41.5)}, "1": {Unit: "°C"}},
+	}, nil)
+	assert.Equal(t, 1, testutil.CollectAndCount(sensorChannelGaugeVec))
+
+	parseSensorMinMaxData(map[string]map[string]fronius.SensorChannelMinMax{
+		"1": {"0": {Unit: "°C", DayMin: floatPtr(8.3), DayMax: floatPtr(45.1)}},
+	}, nil)
+	assert.Equal(t, 2, testutil.CollectAndCount(sensorChannelExtremeGaugeVec))
+}
+
+func Test_collectInverterRealtimeData_GivenInverterNoLongerActive_ThenDeleteItsMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		payload, err := os.ReadFile("pkg/fronius/testdata/realtimedata.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+	defer server.Close()
+	client, err := fronius.NewSymoClient(fronius.ClientOptions{
+		URL:                     server.URL,
+		InverterRealtimeEnabled: true,
+		InverterDataCollections: []string{fronius.CommonInverterDataCollection},
+	})
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	collectInverterRealtimeData(context.Background(), client, &fronius.ActiveDeviceInfo{
+		Inverters: map[string]fronius.ActiveDevice{"1": {}, "2": {}},
+	}, &wg)
+	assert.Equal(t, 2, testutil.CollectAndCount(siteRealtimeDataAcPowerGaugeVec))
+
+	wg.Add(1)
+	collectInverterRealtimeData(context.Background(), client, &fronius.ActiveDeviceInfo{
+		Inverters: map[string]fronius.ActiveDevice{"1": {}},
+	}, &wg)
+	assert.Equal(t, 1, testutil.CollectAndCount(siteRealtimeDataAcPowerGaugeVec))
+	assert.Equal(t, 253.71487426757812, testutil.ToFloat64(siteRealtimeDataAcPowerGaugeVec.WithLabelValues("1")))
+}
+
+func Test_parseStorageRealtimeData_GivenStorageRemoved_ThenDeleteItsMetrics(t *testing.T) {
+	storage := fronius.StorageRealtimeData{}
+	storage.Controller.StateOfCharge = floatPtr(50)
+	storage.Controller.TemperatureCell = floatPtr(23)
+	parseStorageRealtimeData(map[string]fronius.StorageRealtimeData{"0": storage, "1": storage})
+	assert.Equal(t, 2, testutil.CollectAndCount(storageChargeGaugeVec))
+
+	parseStorageRealtimeData(map[string]fronius.StorageRealtimeData{"0": storage})
+	for name, vec := range map[string]*prometheus.GaugeVec{
+		"info":             storageInfoGaugeVec,
+		"state of charge":  storageChargeGaugeVec,
+		"cell temperature": storageCellTemperatureGaugeVec,
+	} {
+		assert.Equal(t, 1, testutil.CollectAndCount(vec), name)
+	}
+}
+
+func Test_parseOhmpilotRealtimeData_GivenOhmpilotRemoved_ThenDeleteItsMetrics(t *testing.T) {
+	ohmpilot := fronius.OhmpilotRealtimeData{Power: floatPtr(1523.5), Temperature: floatPtr(53.9), EnergyConsumed: floatPtr(2964307)}
+	parseOhmpilotRealtimeData(map[string]fronius.OhmpilotRealtimeData{"0": ohmpilot, "1": ohmpilot})
+	assert.Equal(t, 2, testutil.CollectAndCount(ohmpilotPowerGaugeVec))
+	assert.Equal(t, 2, testutil.CollectAndCount(ohmpilotEnergyConsumed))
+
+	parseOhmpilotRealtimeData(map[string]fronius.OhmpilotRealtimeData{"0": ohmpilot})
+	assert.Equal(t, 1, testutil.CollectAndCount(ohmpilotPowerGaugeVec))
+	assert.Equal(t, 1, testutil.CollectAndCount(ohmpilotTemperatureGaugeVec))
+	err := testutil.CollectAndCompare(ohmpilotEnergyConsumed, strings.NewReader(`
+# HELP fronius_ohmpilot_energy_consumed_total Energy consumed by the Ohmpilot in Wh
+# TYPE fronius_ohmpilot_energy_consumed_total counter
+fronius_ohmpilot_energy_consumed_total{ohmpilot="0"} 2.964307e+06
+`))
+	assert.NoError(t, err)
+}
+
+func Test_countRetry_GivenSolarAPIPath_ThenUseScrapeErrorEndpointLabel(t *testing.T) {
+	scrapeRetryCountVec.Reset()
+	countRetry(fronius.PowerDataPath, 1, errors.New("connection reset"))
+	countRetry(fmt.Sprintf(fronius.InverterRealtimeDataPath, "1", "CommonInverterData"), 1, errors.New("connection reset"))
+	countRetry("/solar_api/v1/GetUnknownData.cgi?Scope=System", 1, errors.New("connection reset"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(scrapeRetryCountVec.WithLabelValues("power_flow")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(scrapeRetryCountVec.WithLabelValues("inverter_realtime")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(scrapeRetryCountVec.WithLabelValues("GetUnknownData.cgi")))
+}
+
+func Test_parseArchiveMetrics_GivenInverterRemoved_ThenDeleteItsMPPTMetrics(t *testing.T) {
+	inverter := fronius.InverterArchive{Data: map[string]fronius.Channel{
+		"Current_DC_String_1": {Unit: "A", Values: map[string]*float64{"0": floatPtr(3.25)}},
+		"Voltage_DC_String_1": {Unit: "V", Values: map[string]*float64{"0": floatPtr(425.6)}},
+	}}
+	parseArchiveMetrics(map[string]fronius.InverterArchive{"inverter/1": inverter, "inverter/2": inverter})
+	assert.Equal(t, 2, testutil.CollectAndCount(siteMPPTCurrentDCGaugeVec))
+	assert.Equal(t, 2, testutil.CollectAndCount(siteMPPTVoltageGaugeVec))
+
+	parseArchiveMetrics(map[string]fronius.InverterArchive{"inverter/1": inverter})
+	assert.Equal(t, 1, testutil.CollectAndCount(siteMPPTCurrentDCGaugeVec))
+	assert.Equal(t, 1, testutil.CollectAndCount(siteMPPTVoltageGaugeVec))
+	assert.Equal(t, 425.6, testutil.ToFloat64(siteMPPTVoltageGaugeVec.WithLabelValues("1", "1")))
+}
@@ -0,0 +1,193 @@
+package fronius
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// MaxArchiveRange is the longest period the Solar API returns archive data for in a single request.
+const MaxArchiveRange = 16 * 24 * time.Hour
+
+type (
+	// ArchiveSeries is the time series of an archive channel of a device.
+	ArchiveSeries struct {
+		// Device is the key of the device in the archive, e.g. inverter/1.
+		Device  string
+		Channel string
+		Unit    string
+		// Samples are sorted by time.
+		Samples []Sample
+	}
+	// Sample is a value of an archive channel at a point in time.
+	Sample struct {
+		Timestamp time.Time
+		Value     float64
+	}
+)
+
+// GetArchiveHistory returns the configured archive channels of all devices between start and end from the Symo device.
+// Ranges longer than MaxArchiveRange are split into multiple requests.
+// The series are sorted by device and channel.
+func (c *SymoClient) GetArchiveHistory(start, end time.Time) ([]ArchiveSeries, error) {
+	return c.GetArchiveHistoryWithContext(context.Background(), start, end)
+}
+
+// GetArchiveHistoryWithContext is like GetArchiveHistory, but aborts the requests when the given context is done.
+func (c *SymoClient) GetArchiveHistoryWithContext(ctx context.Context, start, end time.Time) ([]ArchiveSeries, error) {
+	if end.Before(start) {
+		return nil, fmt.Errorf("end of archive range %s is before start %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+
+	merged := map[string]map[string]*ArchiveSeries{}
+	for _, chunk := range archiveChunks(start, end) {
+		data, err := c.getArchive(ctx, chunk[0], chunk[1])
+		if err != nil {
+			return nil, fmt.Errorf("cannot get archive from %s to %s: %w", chunk[0].Format(time.RFC3339), chunk[1].Format(time.RFC3339), err)
+		}
+		for device, node := range data {
+			if merged[device] == nil {
+				merged[device] = map[string]*ArchiveSeries{}
+			}
+			for name, channel := range node.Data {
+				series := merged[device][name]
+				if series == nil {
+					series = &ArchiveSeries{Device: device, Channel: name, Unit: channel.Unit}
+					merged[device][name] = series
+				}
+				series.Samples = append(series.Samples, channel.samples(node.Start)...)
+			}
+		}
+	}
+
+	var result []ArchiveSeries
+	for _, channels := range merged {
+		for _, series := range channels {
+			series.Samples = sortSamples(series.Samples)
+			result = append(result, *series)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Device != result[j].Device {
+			return result[i].Device < result[j].Device
+		}
+		return result[i].Channel < result[j].Channel
+	})
+	return result, nil
+}
+
+// archiveChunks splits the range between start and end into consecutive ranges no longer than MaxArchiveRange.
+// The bounds of each range are inclusive with a resolution of one second, like the StartDate and EndDate of the Solar API.
+func archiveChunks(start, end time.Time) [][2]time.Time {
+	var chunks [][2]time.Time
+	for chunkStart := start; !chunkStart.After(end); {
+		chunkEnd := chunkStart.Add(MaxArchiveRange - time.Second)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		chunks = append(chunks, [2]time.Time{chunkStart, chunkEnd})
+		chunkStart = chunkEnd.Add(time.Second)
+	}
+	return chunks
+}
+
+// samples converts the values of the channel to samples, using their offset in seconds from the given start.
+// Null values are skipped.
+func (c Channel) samples(start time.Time) []Sample {
+	samples := make([]Sample, 0, len(c.Values))
+	for key, value := range c.Values {
+		offset, err := strconv.Atoi(key)
+		if err != nil || value == nil {
+			continue
+		}
+		samples = append(samples, Sample{
+			Timestamp: start.Add(time.Duration(offset) * time.Second),
+			Value:     *value,
+		})
+	}
+	return samples
+}
+
+// sortSamples sorts the given samples by time and removes samples with a duplicate timestamp, e.g. from overlapping chunks.
+func sortSamples(samples []Sample) []Sample {
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+	result := samples[:0]
+	for _, sample := range samples {
+		if len(result) > 0 && sample.Timestamp.Equal(result[len(result)-1].Timestamp) {
+			continue
+		}
+		result = append(result, sample)
+	}
+	return result
+}
+
+// DeviceLocation returns the timezone of the device used for archive requests.
+// This is ClientOptions.Location if set, otherwise the timezone detected from the last archive response,
+// or time.Local if no archive has been requested yet.
+func (c *SymoClient) DeviceLocation() *time.Location {
+	if c.Options.Location != nil {
+		return c.Options.Location
+	}
+	if location := c.getDetectedLocation(); location != nil {
+		return location
+	}
+	return time.Local
+}
+
+// DetectDeviceLocation returns the timezone of the device.
+// See DetectDeviceLocationWithContext.
+func (c *SymoClient) DetectDeviceLocation() (*time.Location, error) {
+	return c.DetectDeviceLocationWithContext(context.Background())
+}
+
+// DetectDeviceLocationWithContext returns ClientOptions.Location if set, or the timezone detected from a previous archive response.
+// Otherwise, it requests a short archive range to detect the timezone.
+// UTC offsets differ by at most 26 hours, so the range is requested 27 hours ago to be in the past for the device in any timezone.
+// It returns nil if the device returned no archive data to detect the timezone from.
+func (c *SymoClient) DetectDeviceLocationWithContext(ctx context.Context) (*time.Location, error) {
+	if c.Options.Location != nil {
+		return c.Options.Location, nil
+	}
+	if location := c.getDetectedLocation(); location != nil {
+		return location, nil
+	}
+	start := time.Now().Add(-27 * time.Hour).Truncate(5 * time.Minute)
+	if _, err := c.getArchiveIn(ctx, start, start.Add(5*time.Minute-time.Second)); err != nil {
+		return nil, err
+	}
+	return c.getDetectedLocation(), nil
+}
+
+func (c *SymoClient) getDetectedLocation() *time.Location {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.detectedLocation
+}
+
+// detectLocation remembers the UTC offset of the archive nodes as the device's timezone, unless it is configured.
+func (c *SymoClient) detectLocation(data map[string]InverterArchive) {
+	if c.Options.Location != nil {
+		return
+	}
+	for _, node := range data {
+		if node.Start.IsZero() {
+			continue
+		}
+		name, offset := node.Start.Zone()
+		c.mu.Lock()
+		c.detectedLocation = time.FixedZone(name, offset)
+		c.mu.Unlock()
+		return
+	}
+}
+
+// sameOffset returns true if both locations have the same UTC offset at the given time.
+func sameOffset(a, b *time.Location, t time.Time) bool {
+	_, offsetA := t.In(a).Zone()
+	_, offsetB := t.In(b).Zone()
+	return offsetA == offsetB
+}
@@ -0,0 +1,247 @@
+package fronius
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Symo_GetArchiveHistory_GivenLongRange_WhenRequestData_ThenSplitAndMergeChunks(t *testing.T) {
+	var requested [][2]string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		assert.Equal(t, []string{"PowerReal_PAC_Sum"}, query["Channel"])
+		requested = append(requested, [2]string{query.Get("StartDate"), query.Get("EndDate")})
+
+		start, err := time.Parse(time.RFC3339, query.Get("StartDate"))
+		require.NoError(t, err)
+		response := map[string]interface{}{
+			"Body": map[string]interface{}{
+				"Data": map[string]interface{}{
+					"inverter/1": map[string]interface{}{
+						"Start": start.Format(time.RFC3339),
+						"End":   query.Get("EndDate"),
+						"Data": map[string]interface{}{
+							"PowerReal_PAC_Sum": map[string]interface{}{
+								"Unit":   "W",
+								"Values": map[string]float64{"300": float64(start.Day()) + 0.5, "0": float64(start.Day())},
+							},
+						},
+					},
+				},
+			},
+			"Head": map[string]interface{}{"Status": map[string]interface{}{"Code": 0}},
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(response))
+	}))
+	defer server.Close()
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:             server.URL,
+		ArchiveChannels: []string{"PowerReal_PAC_Sum"},
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 2, 9, 23, 59, 59, 0, time.UTC)
+	series, err := c.GetArchiveHistory(start, end)
+	require.NoError(t, err)
+
+	assert.Equal(t, [][2]string{
+		{"2024-01-01T00:00:00Z", "2024-01-16T23:59:59Z"},
+		{"2024-01-17T00:00:00Z", "2024-02-01T23:59:59Z"},
+		{"2024-02-02T00:00:00Z", "2024-02-09T23:59:59Z"},
+	}, requested)
+	require.Len(t, series, 1)
+	assert.Equal(t, "inverter/1", series[0].Device)
+	assert.Equal(t, "PowerReal_PAC_Sum", series[0].Channel)
+	assert.Equal(t, "W", series[0].Unit)
+	assert.Equal(t, []Sample{
+		{Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Value: 1},
+		{Timestamp: time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC), Value: 1.5},
+		{Timestamp: time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC), Value: 17},
+		{Timestamp: time.Date(2024, 1, 17, 0, 5, 0, 0, time.UTC), Value: 17.5},
+		{Timestamp: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), Value: 2},
+		{Timestamp: time.Date(2024, 2, 2, 0, 5, 0, 0, time.UTC), Value: 2.5},
+	}, series[0].Samples)
+}
+
+func Test_Symo_GetArchiveHistory_GivenEndBeforeStart_ThenReturnError(t *testing.T) {
+	c, err := NewSymoClient(ClientOptions{})
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	_, err = c.GetArchiveHistory(start, start.Add(-time.Second))
+	assert.EqualError(t, err, "end of archive range 2024-01-01T23:59:59Z is before start 2024-01-02T00:00:00Z")
+}
+
+func Test_archiveChunks(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		end      time.Time
+		expected int
+	}{
+		"GivenSameStartAndEnd_ThenReturnSingleChunk": {end: start, expected: 1},
+		"GivenOneDay_ThenReturnSingleChunk":          {end: start.Add(24*time.Hour - time.Second), expected: 1},
+		"GivenExactlyMaxRange_ThenReturnSingleChunk": {end: start.Add(MaxArchiveRange - time.Second), expected: 1},
+		"GivenMaxRangePlusOneSecond_ThenReturnTwo":   {end: start.Add(MaxArchiveRange), expected: 2},
+		"GivenOneYear_ThenReturnChunksOfMaxRange":    {end: start.AddDate(1, 0, 0).Add(-time.Second), expected: 23},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			chunks := archiveChunks(start, tt.end)
+			require.Len(t, chunks, tt.expected)
+			assert.Equal(t, start, chunks[0][0])
+			assert.Equal(t, tt.end, chunks[len(chunks)-1][1])
+			for i, chunk := range chunks {
+				assert.LessOrEqual(t, chunk[1].Sub(chunk[0]), MaxArchiveRange-time.Second)
+				if i > 0 {
+					assert.Equal(t, chunks[i-1][1].Add(time.Second), chunk[0])
+				}
+			}
+		})
+	}
+}
+
+func Test_sortSamples(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Timestamp: t0.Add(2 * time.Minute), Value: 3},
+		{Timestamp: t0, Value: 1},
+		{Timestamp: t0.Add(time.Minute), Value: 2},
+		{Timestamp: t0, Value: 1},
+	}
+	assert.Equal(t, []Sample{
+		{Timestamp: t0, Value: 1},
+		{Timestamp: t0.Add(time.Minute), Value: 2},
+		{Timestamp: t0.Add(2 * time.Minute), Value: 3},
+	}, sortSamples(samples))
+}
+
+// newTimezoneServer returns a server that interprets the requested archive range in the given timezone like a Datamanager,
+// and records the raw StartDate parameters.
+func newTimezoneServer(t *testing.T, location *time.Location) (*httptest.Server, *[]string) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// The raw query is used, since the offset in the dates is not escaped.
+		_, rawStart, _ := strings.Cut(req.URL.RawQuery, "StartDate=")
+		rawStart, _, _ = strings.Cut(rawStart, "&")
+		requested = append(requested, rawStart)
+
+		// The device ignores the offset and takes the wall clock in its own timezone.
+		wallClock, err := time.Parse("2006-01-02T15:04:05", rawStart[:19])
+		require.NoError(t, err)
+		start := time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(), wallClock.Hour(), wallClock.Minute(), wallClock.Second(), 0, location)
+		response := map[string]interface{}{
+			"Body": map[string]interface{}{
+				"Data": map[string]interface{}{
+					"inverter/1": map[string]interface{}{
+						"Start": start.Format(time.RFC3339),
+						"End":   start.Add(5*time.Minute - time.Second).Format(time.RFC3339),
+						"Data": map[string]interface{}{
+							"Voltage_DC_String_1": map[string]interface{}{"Unit": "V", "Values": map[string]float64{"0": 425.6}},
+						},
+					},
+				},
+			},
+			"Head": map[string]interface{}{"Status": map[string]interface{}{"Code": 0}},
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requested
+}
+
+func Test_Symo_GetArchiveData_GivenNoLocation_WhenDeviceInOtherTimezone_ThenDetectTimezone(t *testing.T) {
+	// An unusual offset to make sure it differs from the timezone of the host running the test.
+	kathmandu := time.FixedZone("", 5*3600+45*60)
+	server, requested := newTimezoneServer(t, kathmandu)
+
+	c, err := NewSymoClient(ClientOptions{
+		URL: server.URL,
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetArchiveData()
+	require.NoError(t, err)
+	assert.Equal(t, floatPtr(425.6), p["inverter/1"].Data["Voltage_DC_String_1"].Values["0"])
+	require.Len(t, *requested, 2, "the request should be repeated in the detected timezone")
+	assert.True(t, strings.HasSuffix((*requested)[1], "+05:45"), (*requested)[1])
+	assert.True(t, sameOffset(kathmandu, c.DeviceLocation(), time.Now()))
+
+	_, err = c.GetArchiveData()
+	require.NoError(t, err)
+	require.Len(t, *requested, 3, "the detected timezone should be reused")
+	assert.True(t, strings.HasSuffix((*requested)[2], "+05:45"), (*requested)[2])
+}
+
+func Test_Symo_GetArchiveData_GivenLocation_WhenRequestData_ThenUseLocation(t *testing.T) {
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	require.NoError(t, err)
+	server, requested := newTimezoneServer(t, zurich)
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:      server.URL,
+		Location: zurich,
+	})
+	require.NoError(t, err)
+
+	_, err = c.GetArchiveData()
+	require.NoError(t, err)
+	require.Len(t, *requested, 1)
+	start, err := time.Parse(time.RFC3339, (*requested)[0])
+	require.NoError(t, err)
+	_, expectedOffset := time.Now().In(zurich).Zone()
+	_, offset := start.Zone()
+	assert.Equal(t, expectedOffset, offset)
+	assert.Equal(t, zurich, c.DeviceLocation())
+}
+
+func Test_Channel_samples_GivenNullValues_ThenSkipThem(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	channel := Channel{Values: map[string]*float64{"0": floatPtr(0), "300": nil}}
+	assert.Equal(t, []Sample{{Timestamp: start, Value: 0}}, channel.samples(start))
+}
+
+func Test_Symo_DetectDeviceLocation_GivenNoLocation_WhenDeviceInOtherTimezone_ThenDetectTimezone(t *testing.T) {
+	kathmandu := time.FixedZone("", 5*3600+45*60)
+	server, requested := newTimezoneServer(t, kathmandu)
+
+	c, err := NewSymoClient(ClientOptions{
+		URL: server.URL,
+	})
+	require.NoError(t, err)
+
+	location, err := c.DetectDeviceLocation()
+	require.NoError(t, err)
+	require.NotNil(t, location)
+	assert.True(t, sameOffset(kathmandu, location, time.Now()))
+	require.Len(t, *requested, 1)
+
+	_, err = c.DetectDeviceLocation()
+	require.NoError(t, err)
+	assert.Len(t, *requested, 1, "the detected timezone should be reused")
+}
+
+func Test_Symo_DetectDeviceLocation_GivenLocation_ThenDontRequestArchive(t *testing.T) {
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	require.NoError(t, err)
+	server, requested := newTimezoneServer(t, zurich)
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:      server.URL,
+		Location: zurich,
+	})
+	require.NoError(t, err)
+
+	location, err := c.DetectDeviceLocation()
+	require.NoError(t, err)
+	assert.Equal(t, zurich, location)
+	assert.Empty(t, *requested)
+}
@@ -0,0 +1,204 @@
+package fronius
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type (
+	// digestTransport is a http.RoundTripper that answers HTTP Digest authentication challenges (RFC 7616).
+	// The last challenge is remembered, so that subsequent requests are authorized without another round-trip.
+	digestTransport struct {
+		username string
+		password string
+		next     http.RoundTripper
+
+		mu         sync.Mutex
+		challenge  *digestChallenge
+		nonceCount int
+	}
+	digestChallenge struct {
+		realm     string
+		nonce     string
+		opaque    string
+		algorithm string
+		qop       string
+	}
+)
+
+// newDigestTransport returns a digestTransport that authorizes requests sent through next with the given credentials.
+func newDigestTransport(username, password string, next http.RoundTripper) *digestTransport {
+	return &digestTransport{
+		username: username,
+		password: password,
+		next:     next,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, err := t.next.RoundTrip(t.authorize(req))
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+	challenge := parseDigestChallenge(response.Header)
+	if challenge == nil || (req.Body != nil && req.GetBody == nil) {
+		return response, nil
+	}
+	_ = response.Body.Close()
+
+	t.mu.Lock()
+	t.challenge = challenge
+	t.nonceCount = 0
+	t.mu.Unlock()
+
+	retry := t.authorize(req)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+	return t.next.RoundTrip(retry)
+}
+
+// authorize returns a copy of the given request with an Authorization header answering the last known challenge.
+// The request is returned unmodified if no challenge has been received yet.
+func (t *digestTransport) authorize(req *http.Request) *http.Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.challenge == nil {
+		return req
+	}
+	t.nonceCount++
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", t.challenge.authorization(t.username, t.password, req.Method, req.URL.RequestURI(), t.nonceCount))
+	return authorized
+}
+
+// parseDigestChallenge returns the Digest challenge from the given response headers, or nil if there is none.
+// Gen24 firmware sends the challenge in X-WWW-Authenticate to avoid the browser's login dialog, so both headers are considered.
+func parseDigestChallenge(header http.Header) *digestChallenge {
+	for _, key := range []string{"WWW-Authenticate", "X-WWW-Authenticate"} {
+		for _, value := range header.Values(key) {
+			scheme, params, found := strings.Cut(strings.TrimSpace(value), " ")
+			if !found || !strings.EqualFold(scheme, "Digest") {
+				continue
+			}
+			p := parseDigestParams(params)
+			challenge := &digestChallenge{
+				realm:     p["realm"],
+				nonce:     p["nonce"],
+				opaque:    p["opaque"],
+				algorithm: p["algorithm"],
+			}
+			for _, qop := range strings.Split(p["qop"], ",") {
+				if strings.TrimSpace(qop) == "auth" {
+					challenge.qop = "auth"
+				}
+			}
+			if challenge.newHash() == nil {
+				continue
+			}
+			return challenge
+		}
+	}
+	return nil
+}
+
+// parseDigestParams splits the comma-separated key=value pairs of a challenge, respecting quoted values.
+func parseDigestParams(s string) map[string]string {
+	params := map[string]string{}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ,")
+		key, rest, found := strings.Cut(s, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, s = rest[1:], ""
+			} else {
+				value, s = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+	return params
+}
+
+// newHash returns the hash function of the challenge's algorithm, or nil if it isn't supported.
+func (c *digestChallenge) newHash() func() hash.Hash {
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(c.algorithm), "-sess")) {
+	case "", "MD5":
+		return md5.New
+	case "SHA-256":
+		return sha256.New
+	default:
+		return nil
+	}
+}
+
+// authorization computes the value of the Authorization header for the given request.
+func (c *digestChallenge) authorization(username, password, method, uri string, nonceCount int) string {
+	newHash := c.newHash()
+	h := func(s string) string {
+		sum := newHash()
+		sum.Write([]byte(s))
+		return hex.EncodeToString(sum.Sum(nil))
+	}
+
+	nc := fmt.Sprintf("%08x", nonceCount)
+	cnonce := newClientNonce()
+	ha1 := h(username + ":" + c.realm + ":" + password)
+	if strings.HasSuffix(strings.ToLower(c.algorithm), "-sess") {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := h(method + ":" + uri)
+
+	var response string
+	if c.qop == "" {
+		response = h(ha1 + ":" + c.nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":" + c.qop + ":" + ha2)
+	}
+
+	fields := []string{
+		fmt.Sprintf("username=%q", username),
+		fmt.Sprintf("realm=%q", c.realm),
+		fmt.Sprintf("nonce=%q", c.nonce),
+		fmt.Sprintf("uri=%q", uri),
+		fmt.Sprintf("response=%q", response),
+	}
+	if c.algorithm != "" {
+		fields = append(fields, "algorithm="+c.algorithm)
+	}
+	if c.opaque != "" {
+		fields = append(fields, fmt.Sprintf("opaque=%q", c.opaque))
+	}
+	if c.qop != "" {
+		fields = append(fields, "qop="+c.qop, "nc="+nc, fmt.Sprintf("cnonce=%q", cnonce))
+	}
+	return "Digest " + strings.Join(fields, ", ")
+}
+
+func newClientNonce() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,134 @@
+package fronius
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDigestServer returns a server that requires HTTP Digest authentication with the given credentials.
+// The challenge is sent in the given header and a new nonce is issued with every challenge.
+func newDigestServer(t *testing.T, username, password, algorithm, challengeHeader string) (*httptest.Server, *atomic.Int32) {
+	challenges := &atomic.Int32{}
+	newHash := md5.New
+	if algorithm == "SHA-256" {
+		newHash = sha256.New
+	}
+	h := func(s string) string {
+		return hexHash(newHash(), s)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		auth := req.Header.Get("Authorization")
+		if strings.HasPrefix(auth, "Digest ") {
+			p := parseDigestParams(strings.TrimPrefix(auth, "Digest "))
+			ha1 := h(username + ":" + p["realm"] + ":" + password)
+			ha2 := h(req.Method + ":" + req.URL.RequestURI())
+			expected := h(strings.Join([]string{ha1, p["nonce"], p["nc"], p["cnonce"], p["qop"], ha2}, ":"))
+			if p["username"] == username && p["response"] == expected && p["opaque"] == "opaque-value" &&
+				p["nonce"] == fmt.Sprintf("nonce-%d", challenges.Load()) {
+				payload, err := os.ReadFile("testdata/example_1.json")
+				require.NoError(t, err)
+				_, _ = rw.Write(payload)
+				return
+			}
+		}
+		nonce := challenges.Add(1)
+		rw.Header().Set(challengeHeader, fmt.Sprintf(`Digest realm="Webinterface area", nonce="nonce-%d", qop="auth", algorithm=%s, opaque="opaque-value"`, nonce, algorithm))
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+	return server, challenges
+}
+
+func hexHash(h hash.Hash, s string) string {
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func Test_Symo_GivenDigestChallenge_WhenCredentialsValid_ThenAuthorize(t *testing.T) {
+	tests := map[string]struct {
+		algorithm       string
+		challengeHeader string
+	}{
+		"GivenMD5_ThenAuthorize":                  {algorithm: "MD5", challengeHeader: "WWW-Authenticate"},
+		"GivenSHA256_ThenAuthorize":               {algorithm: "SHA-256", challengeHeader: "WWW-Authenticate"},
+		"GivenGen24ChallengeHeader_ThenAuthorize": {algorithm: "MD5", challengeHeader: "X-WWW-Authenticate"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, challenges := newDigestServer(t, "customer", "secret", tt.algorithm, tt.challengeHeader)
+
+			c, err := NewSymoClient(ClientOptions{
+				URL:      server.URL,
+				Username: "customer",
+				Password: "secret",
+			})
+			require.NoError(t, err)
+
+			for i := 0; i < 3; i++ {
+				p, err := c.GetPowerFlowData()
+				require.NoError(t, err)
+				assert.Equal(t, floatPtr(34.5), p.Inverters["1"].BatterySoC)
+			}
+			assert.Equal(t, int32(1), challenges.Load(), "the challenge should be reused for subsequent requests")
+		})
+	}
+}
+
+func Test_Symo_GivenDigestChallenge_WhenPasswordWrong_ThenReturnHTTPError(t *testing.T) {
+	server, _ := newDigestServer(t, "customer", "secret", "MD5", "WWW-Authenticate")
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:      server.URL,
+		Username: "customer",
+		Password: "wrong",
+	})
+	require.NoError(t, err)
+
+	_, err = c.GetPowerFlowData()
+	var httpErr *HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+}
+
+func Test_parseDigestChallenge(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		expected *digestChallenge
+	}{
+		"GivenFullChallenge_ThenParseAllParams": {
+			header: `Digest realm="Webinterface area", nonce="abc,def", qop="auth,auth-int", algorithm=MD5-sess, opaque="xyz"`,
+			expected: &digestChallenge{
+				realm: "Webinterface area", nonce: "abc,def", qop: "auth", algorithm: "MD5-sess", opaque: "xyz",
+			},
+		},
+		"GivenNoQop_ThenLeaveQopEmpty": {
+			header:   `Digest realm="r", nonce="n"`,
+			expected: &digestChallenge{realm: "r", nonce: "n"},
+		},
+		"GivenBasicChallenge_ThenReturnNil": {
+			header: `Basic realm="r"`,
+		},
+		"GivenUnsupportedAlgorithm_ThenReturnNil": {
+			header: `Digest realm="r", nonce="n", algorithm=SHA-512-256`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("WWW-Authenticate", tt.header)
+			assert.Equal(t, tt.expected, parseDigestChallenge(header))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package fronius
+
+import "context"
+
+const (
+	// OhmpilotRealtimeDataPath is the Fronius API URL-path for real time data of all Ohmpilot devices
+	OhmpilotRealtimeDataPath = "/solar_api/v1/GetOhmPilotRealtimeData.cgi?Scope=System"
+)
+
+type (
+	symoOhmpilot struct {
+		Body struct {
+			Data map[string]OhmpilotRealtimeData `json:"Data"`
+		}
+	}
+	// OhmpilotRealtimeData holds the real time data of an Ohmpilot, which diverts surplus power into a heating element.
+	// Values that the device omits or reports as null are nil.
+	OhmpilotRealtimeData struct {
+		Details DeviceDetails `json:"Details"`
+		// CodeOfState is the operating state of the Ohmpilot, see State for a human-readable form.
+		CodeOfState float64 `json:"CodeOfState"`
+		// CodeOfError is the error code of the Ohmpilot, nil unless the Ohmpilot is in fault or warning state.
+		CodeOfError *float64 `json:"CodeOfError"`
+		// EnergyConsumed is the accumulated energy in Wh consumed by the Ohmpilot.
+		EnergyConsumed *float64 `json:"EnergyReal_WAC_Sum_Consumed"`
+		// Power is the current power consumption in Watt.
+		Power *float64 `json:"PowerReal_PAC_Sum"`
+		// Temperature is the temperature measured by the attached sensor in degree Celsius.
+		Temperature *float64 `json:"Temperature_Channel_1"`
+	}
+)
+
+// GetOhmpilotRealtimeData returns the parsed data of all Ohmpilot devices keyed by device ID from the Symo device.
+func (c *SymoClient) GetOhmpilotRealtimeData() (map[string]OhmpilotRealtimeData, error) {
+	return c.GetOhmpilotRealtimeDataWithContext(context.Background())
+}
+
+// GetOhmpilotRealtimeDataWithContext is like GetOhmpilotRealtimeData, but aborts the request when the given context is done.
+func (c *SymoClient) GetOhmpilotRealtimeDataWithContext(ctx context.Context) (map[string]OhmpilotRealtimeData, error) {
+	p := symoOhmpilot{}
+	if err := c.fetch(ctx, OhmpilotRealtimeDataPath, &p); err != nil {
+		return nil, err
+	}
+	return p.Body.Data, nil
+}
+
+// State returns the human-readable operating state of the Ohmpilot's CodeOfState.
+func (o OhmpilotRealtimeData) State() string {
+	switch int(o.CodeOfState) {
+	case 0:
+		return "Running"
+	case 1:
+		return "MinimumTemperature"
+	case 2:
+		return "LegionellaProtection"
+	case 3:
+		return "Fault"
+	case 4:
+		return "Warning"
+	case 5:
+		return "Boost"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,41 @@
+package fronius
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Symo_GetOhmpilotRealtimeData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "System", req.URL.Query().Get("Scope"))
+		payload, err := os.ReadFile("testdata/ohmpilotrealtimedata.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                     server.URL,
+		OhmpilotRealtimeEnabled: true,
+	})
+	require.NoError(t, err)
+
+	ohmpilots, err := c.GetOhmpilotRealtimeData()
+	assert.NoError(t, err)
+	require.Len(t, ohmpilots, 2)
+
+	p := ohmpilots["0"]
+	assert.Equal(t, "28136300", p.Details.Serial)
+	assert.Equal(t, "Running", p.State())
+	assert.Nil(t, p.CodeOfError, "the error code is only reported in fault or warning state")
+	assert.Equal(t, floatPtr(2964307), p.EnergyConsumed)
+	assert.Equal(t, floatPtr(1523.5), p.Power)
+	assert.Equal(t, floatPtr(53.9), p.Temperature)
+
+	assert.Equal(t, "Fault", ohmpilots["1"].State())
+	assert.Equal(t, floatPtr(926), ohmpilots["1"].CodeOfError)
+}
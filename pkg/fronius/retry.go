@@ -0,0 +1,67 @@
+package fronius
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// maxRetryBackoff caps the exponentially growing delay between two attempts.
+const maxRetryBackoff = 5 * time.Second
+
+// fetch requests the given API path like fetchOnce, but repeats failed requests up to ClientOptions.Retries times.
+// The delay between attempts starts at ClientOptions.RetryBackoff and is doubled with each attempt, randomized by jitter.
+// No further attempt is made if it could not complete before the deadline of the given context.
+func (c *SymoClient) fetch(ctx context.Context, path string, v interface{}) error {
+	backoff := c.Options.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := c.fetchOnce(ctx, path, v)
+		if err == nil || attempt > c.Options.Retries || !isRetryable(ctx, err) {
+			return err
+		}
+
+		delay := withJitter(backoff)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		if c.Options.OnRetry != nil {
+			c.Options.OnRetry(path, attempt, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// isRetryable returns true if the given error is likely caused by a transient failure of the device.
+// Errors reported by the Solar API, client-side HTTP errors and malformed responses are not retried.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+	var apiErr *APIError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return !errors.As(err, &apiErr) && !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr)
+}
+
+// withJitter returns a random duration between half and the full given backoff.
+func withJitter(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
@@ -0,0 +1,119 @@
+package fronius
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer returns a server that drops the connection of the first failures requests and serves the given file afterwards.
+func newFlakyServer(t *testing.T, failures int32, file string) (*httptest.Server, *atomic.Int32) {
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if requests.Add(1) <= failures {
+			conn, _, err := rw.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			_ = conn.Close()
+			return
+		}
+		payload, err := os.ReadFile(file)
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func Test_Symo_GivenDroppedConnections_WhenRetriesLeft_ThenSucceed(t *testing.T) {
+	server, requests := newFlakyServer(t, 2, "testdata/example_1.json")
+
+	var retries []int
+	c, err := NewSymoClient(ClientOptions{
+		URL:          server.URL,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		OnRetry: func(path string, attempt int, err error) {
+			assert.Equal(t, PowerDataPath, path)
+			assert.Error(t, err)
+			retries = append(retries, attempt)
+		},
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetPowerFlowData()
+	require.NoError(t, err)
+	assert.Equal(t, floatPtr(34.5), p.Inverters["1"].BatterySoC)
+	assert.Equal(t, int32(3), requests.Load())
+	assert.Equal(t, []int{1, 2}, retries)
+}
+
+func Test_Symo_GivenDroppedConnections_WhenNoRetriesLeft_ThenReturnError(t *testing.T) {
+	server, requests := newFlakyServer(t, 3, "testdata/example_1.json")
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:          server.URL,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, err = c.GetPowerFlowData()
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func Test_Symo_GivenClientError_WhenRequestData_ThenDoNotRetry(t *testing.T) {
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:          server.URL,
+		Retries:      3,
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, err = c.GetPowerFlowData()
+	var httpErr *HTTPError
+	assert.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func Test_Symo_GivenDeadline_WhenBackoffExceedsDeadline_ThenStopRetrying(t *testing.T) {
+	server, requests := newFlakyServer(t, 1, "testdata/example_1.json")
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:          server.URL,
+		Retries:      3,
+		RetryBackoff: time.Minute,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err = c.GetPowerFlowDataWithContext(ctx)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func Test_withJitter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), withJitter(0))
+	for i := 0; i < 100; i++ {
+		delay := withJitter(100 * time.Millisecond)
+		assert.GreaterOrEqual(t, delay, 50*time.Millisecond)
+		assert.LessOrEqual(t, delay, 100*time.Millisecond)
+	}
+}
@@ -0,0 +1,72 @@
+package fronius
+
+import "context"
+
+const (
+	// SensorRealtimeDataPath is the Fronius API URL-path for the current values of all sensor card channels
+	SensorRealtimeDataPath = "/solar_api/v1/GetSensorRealtimeData.cgi?Scope=System&DataCollection=NowSensorData"
+	// SensorMinMaxDataPath is the Fronius API URL-path for the minimum and maximum values of all sensor card channels
+	SensorMinMaxDataPath = "/solar_api/v1/GetSensorRealtimeData.cgi?Scope=System&DataCollection=MinMaxSensorData"
+)
+
+type (
+	symoSensorNow struct {
+		Body struct {
+			Data map[string]map[string]SensorChannel `json:"Data"`
+		}
+	}
+	symoSensorMinMax struct {
+		Body struct {
+			Data map[string]map[string]SensorChannelMinMax `json:"Data"`
+		}
+	}
+	// SensorChannel holds the current value of a sensor card channel.
+	// Values that the device omits or reports as null are nil.
+	SensorChannel struct {
+		Unit  string   `json:"Unit"`
+		Value *float64 `json:"Value"`
+	}
+	// SensorChannelMinMax holds the extreme values of a sensor card channel.
+	// Values that the device omits or reports as null are nil.
+	SensorChannelMinMax struct {
+		Unit     string   `json:"Unit"`
+		DayMin   *float64 `json:"Value_Day_Min"`
+		DayMax   *float64 `json:"Value_Day_Max"`
+		MonthMin *float64 `json:"Value_Month_Min"`
+		MonthMax *float64 `json:"Value_Month_Max"`
+		YearMin  *float64 `json:"Value_Year_Min"`
+		YearMax  *float64 `json:"Value_Year_Max"`
+		TotalMin *float64 `json:"Value_Total_Min"`
+		TotalMax *float64 `json:"Value_Total_Max"`
+	}
+)
+
+// GetSensorRealtimeData returns the current values of all sensor cards from the Symo device.
+// The result is keyed by sensor card ID and then by channel ID.
+func (c *SymoClient) GetSensorRealtimeData() (map[string]map[string]SensorChannel, error) {
+	return c.GetSensorRealtimeDataWithContext(context.Background())
+}
+
+// GetSensorRealtimeDataWithContext is like GetSensorRealtimeData, but aborts the request when the given context is done.
+func (c *SymoClient) GetSensorRealtimeDataWithContext(ctx context.Context) (map[string]map[string]SensorChannel, error) {
+	p := symoSensorNow{}
+	if err := c.fetch(ctx, SensorRealtimeDataPath, &p); err != nil {
+		return nil, err
+	}
+	return p.Body.Data, nil
+}
+
+// GetSensorMinMaxData returns the extreme values of all sensor cards from the Symo device.
+// The result is keyed by sensor card ID and then by channel ID.
+func (c *SymoClient) GetSensorMinMaxData() (map[string]map[string]SensorChannelMinMax, error) {
+	return c.GetSensorMinMaxDataWithContext(context.Background())
+}
+
+// GetSensorMinMaxDataWithContext is like GetSensorMinMaxData, but aborts the request when the given context is done.
+func (c *SymoClient) GetSensorMinMaxDataWithContext(ctx context.Context) (map[string]map[string]SensorChannelMinMax, error) {
+	p := symoSensorMinMax{}
+	if err := c.fetch(ctx, SensorMinMaxDataPath, &p); err != nil {
+		return nil, err
+	}
+	return p.Body.Data, nil
+}
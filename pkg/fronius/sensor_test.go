@@ -0,0 +1,63 @@
+package fronius
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Symo_GetSensorRealtimeData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "NowSensorData", req.URL.Query().Get("DataCollection"))
+		payload, err := os.ReadFile("testdata/sensorrealtimedata.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                   server.URL,
+		SensorRealtimeEnabled: true,
+	})
+	require.NoError(t, err)
+
+	cards, err := c.GetSensorRealtimeData()
+	assert.NoError(t, err)
+	require.Len(t, cards, 1)
+	require.Len(t, cards["1"], 3)
+	assert.Equal(t, SensorChannel{Unit: "°C", Value: floatPtr(41.5)}, cards["1"]["0"])
+	assert.Equal(t, SensorChannel{Unit: "W/m²", Value: floatPtr(812)}, cards["1"]["2"])
+}
+
+func Test_Symo_GetSensorMinMaxData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "MinMaxSensorData", req.URL.Query().Get("DataCollection"))
+		payload, err := os.ReadFile("testdata/minmaxsensordata.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                   server.URL,
+		SensorRealtimeEnabled: true,
+	})
+	require.NoError(t, err)
+
+	cards, err := c.GetSensorMinMaxData()
+	assert.NoError(t, err)
+	require.Len(t, cards["1"], 2)
+
+	p := cards["1"]["0"]
+	assert.Equal(t, "°C", p.Unit)
+	assert.Equal(t, floatPtr(8.3), p.DayMin)
+	assert.Equal(t, floatPtr(45.1), p.DayMax)
+	assert.Equal(t, floatPtr(2.1), p.MonthMin)
+	assert.Equal(t, floatPtr(52.4), p.MonthMax)
+	assert.Equal(t, floatPtr(-12.6), p.YearMin)
+	assert.Equal(t, floatPtr(61.2), p.YearMax)
+	assert.Equal(t, floatPtr(-19.4), p.TotalMin)
+	assert.Equal(t, floatPtr(68.9), p.TotalMax)
+}
@@ -0,0 +1,152 @@
+package fronius
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBodySnippet is the maximum number of bytes of the response body that are kept in an HTTPError.
+const maxErrorBodySnippet = 256
+
+// StatusCode is the status code reported by the Solar API in the Head.Status block of each response.
+type StatusCode int
+
+const (
+	StatusOkay               StatusCode = 0
+	StatusNotImplemented     StatusCode = 1
+	StatusUninitialized      StatusCode = 2
+	StatusInitialized        StatusCode = 3
+	StatusRunning            StatusCode = 4
+	StatusTimeout            StatusCode = 5
+	StatusArgumentError      StatusCode = 6
+	StatusLNRequestError     StatusCode = 7
+	StatusLNRequestTimeout   StatusCode = 8
+	StatusLNParseError       StatusCode = 9
+	StatusConfigIOError      StatusCode = 10
+	StatusNotSupported       StatusCode = 11
+	StatusDeviceNotAvailable StatusCode = 12
+	StatusUnknownError       StatusCode = 255
+)
+
+type (
+	symoHead struct {
+		Head struct {
+			Status struct {
+				Code        StatusCode `json:"Code"`
+				Reason      string     `json:"Reason"`
+				UserMessage string     `json:"UserMessage"`
+			} `json:"Status"`
+		} `json:"Head"`
+	}
+
+	// APIError is returned by SymoClient if the Solar API reports a non-zero status code in the response head.
+	// The body of such responses is not decoded, since it is usually empty or incomplete.
+	APIError struct {
+		// Path is the API URL-path that has been requested.
+		Path        string
+		Code        StatusCode
+		Reason      string
+		UserMessage string
+	}
+
+	// HTTPError is returned by SymoClient if the device (or a proxy in front of it) responds with an HTTP status other than 2xx,
+	// or with a content type that can't be a Solar API JSON response.
+	HTTPError struct {
+		// Path is the API URL-path that has been requested.
+		Path        string
+		StatusCode  int
+		ContentType string
+		// Body contains the beginning of the response body, truncated to a few hundred bytes.
+		Body string
+	}
+)
+
+// String returns the name of the status code as documented in the Solar API specification.
+func (s StatusCode) String() string {
+	switch s {
+	case StatusOkay:
+		return "Okay"
+	case StatusNotImplemented:
+		return "NotImplemented"
+	case StatusUninitialized:
+		return "Uninitialized"
+	case StatusInitialized:
+		return "Initialized"
+	case StatusRunning:
+		return "Running"
+	case StatusTimeout:
+		return "Timeout"
+	case StatusArgumentError:
+		return "ArgumentError"
+	case StatusLNRequestError:
+		return "LNRequestError"
+	case StatusLNRequestTimeout:
+		return "LNRequestTimeout"
+	case StatusLNParseError:
+		return "LNParseError"
+	case StatusConfigIOError:
+		return "ConfigIOError"
+	case StatusNotSupported:
+		return "NotSupported"
+	case StatusDeviceNotAvailable:
+		return "DeviceNotAvailable"
+	case StatusUnknownError:
+		return "UnknownError"
+	default:
+		return "Invalid"
+	}
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("solar api returned status %d (%s) for %s", e.Code, e.Code, e.Path)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// Error implements error.
+func (e *HTTPError) Error() string {
+	if e.StatusCode < 200 || e.StatusCode > 299 {
+		return fmt.Sprintf("unexpected http status %d for %s: %q", e.StatusCode, e.Path, e.Body)
+	}
+	return fmt.Sprintf("unexpected content type %q for %s: %q", e.ContentType, e.Path, e.Body)
+}
+
+// newHTTPError returns an *HTTPError if the given response isn't a successful JSON response, otherwise nil.
+func newHTTPError(path string, response *http.Response, payload []byte) *HTTPError {
+	contentType := response.Header.Get("Content-Type")
+	if response.StatusCode >= 200 && response.StatusCode <= 299 && isJSONContentType(contentType) {
+		return nil
+	}
+	if len(payload) > maxErrorBodySnippet {
+		payload = payload[:maxErrorBodySnippet]
+	}
+	return &HTTPError{
+		Path:        path,
+		StatusCode:  response.StatusCode,
+		ContentType: contentType,
+		Body:        strings.TrimSpace(string(payload)),
+	}
+}
+
+// isJSONContentType returns true if the given content type may contain a JSON document.
+// Older Datamanager firmware serves JSON as text/javascript or text/plain, so only clearly different types are rejected.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "application/json", "application/javascript", "text/javascript", "text/json", "text/plain":
+		return true
+	default:
+		return false
+	}
+}
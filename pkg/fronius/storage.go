@@ -0,0 +1,67 @@
+package fronius
+
+import "context"
+
+const (
+	// StorageRealtimeDataPath is the Fronius API URL-path for real time data of all storage devices
+	StorageRealtimeDataPath = "/solar_api/v1/GetStorageRealtimeData.cgi?Scope=System"
+)
+
+type (
+	symoStorage struct {
+		Body struct {
+			Data map[string]StorageRealtimeData `json:"Data"`
+		}
+	}
+	// StorageRealtimeData holds the real time data of a storage device (battery).
+	StorageRealtimeData struct {
+		Controller StorageController `json:"Controller"`
+	}
+	// StorageController represents the controller of a storage device.
+	// Values that the device omits or reports as null are nil.
+	StorageController struct {
+		Details DeviceDetails `json:"Details"`
+		// Enabled is 1 if the storage is enabled.
+		Enabled *float64 `json:"Enable"`
+		// StatusBatteryCell is the manufacturer-specific state code of the controller.
+		StatusBatteryCell *float64 `json:"Status_BatteryCell"`
+		// StateOfCharge is the relative charge of the storage in percent.
+		StateOfCharge *float64 `json:"StateOfCharge_Relative"`
+		// CapacityMaximum is the currently usable capacity in Wh.
+		CapacityMaximum *float64 `json:"Capacity_Maximum"`
+		// DesignedCapacity is the nominal capacity of the storage in Wh.
+		DesignedCapacity *float64 `json:"DesignedCapacity"`
+		// CycleCount is the number of full charge cycles of the battery cells.
+		CycleCount *float64 `json:"CycleCount_BatteryCell"`
+		// CurrentDC is the DC current in Ampere.
+		CurrentDC *float64 `json:"Current_DC"`
+		// VoltageDC is the DC voltage in Volt.
+		VoltageDC *float64 `json:"Voltage_DC"`
+		// TemperatureCell is the average temperature of the battery cells in degree Celsius.
+		TemperatureCell *float64 `json:"Temperature_Cell"`
+		// TemperatureCellMaximum is the temperature of the hottest battery cell in degree Celsius.
+		TemperatureCellMaximum *float64 `json:"Temperature_Cell_Maximum"`
+		// TemperatureCellMinimum is the temperature of the coldest battery cell in degree Celsius.
+		TemperatureCellMinimum *float64 `json:"Temperature_Cell_Minimum"`
+	}
+	// DeviceDetails holds the manufacturer information of a device attached to the Datamanager.
+	DeviceDetails struct {
+		Manufacturer string `json:"Manufacturer"`
+		Model        string `json:"Model"`
+		Serial       string `json:"Serial"`
+	}
+)
+
+// GetStorageRealtimeData returns the parsed data of all storage devices keyed by storage ID from the Symo device.
+func (c *SymoClient) GetStorageRealtimeData() (map[string]StorageRealtimeData, error) {
+	return c.GetStorageRealtimeDataWithContext(context.Background())
+}
+
+// GetStorageRealtimeDataWithContext is like GetStorageRealtimeData, but aborts the request when the given context is done.
+func (c *SymoClient) GetStorageRealtimeDataWithContext(ctx context.Context) (map[string]StorageRealtimeData, error) {
+	p := symoStorage{}
+	if err := c.fetch(ctx, StorageRealtimeDataPath, &p); err != nil {
+		return nil, err
+	}
+	return p.Body.Data, nil
+}
@@ -0,0 +1,45 @@
+package fronius
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Symo_GetStorageRealtimeData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "System", req.URL.Query().Get("Scope"))
+		payload, err := os.ReadFile("testdata/storagerealtimedata.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                    server.URL,
+		StorageRealtimeEnabled: true,
+	})
+	require.NoError(t, err)
+
+	storages, err := c.GetStorageRealtimeData()
+	assert.NoError(t, err)
+	require.Len(t, storages, 1)
+
+	p := storages["0"].Controller
+	assert.Equal(t, "BYD", p.Details.Manufacturer)
+	assert.Equal(t, "P030T020Z2009160", p.Details.Serial)
+	assert.Equal(t, floatPtr(1), p.Enabled)
+	assert.Equal(t, floatPtr(3), p.StatusBatteryCell)
+	assert.Equal(t, floatPtr(55.9), p.StateOfCharge)
+	assert.Equal(t, floatPtr(9600), p.CapacityMaximum)
+	assert.Equal(t, floatPtr(10240), p.DesignedCapacity)
+	assert.Equal(t, floatPtr(412), p.CycleCount)
+	assert.Equal(t, floatPtr(-2.3), p.CurrentDC)
+	assert.Equal(t, floatPtr(371.3), p.VoltageDC)
+	assert.Equal(t, floatPtr(23.15), p.TemperatureCell)
+	assert.Equal(t, floatPtr(24.5), p.TemperatureCellMaximum)
+	assert.Equal(t, floatPtr(21.8), p.TemperatureCellMinimum)
+}
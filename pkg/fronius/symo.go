@@ -1,24 +1,48 @@
 package fronius
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"html"
+	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 )
 
 const (
 	// PowerDataPath is the Fronius API URL-path for power real time data
 	PowerDataPath = "/solar_api/v1/GetPowerFlowRealtimeData.fcgi"
-	// ArchiveDataPath is the Fronius API URL-path for archive data
-	ArchiveDataPath = "/solar_api/v1/GetArchiveData.cgi?Scope=System&Channel=Voltage_DC_String_1&Channel=Current_DC_String_1&Channel=Voltage_DC_String_2&Channel=Current_DC_String_2&HumanReadable=false"
-	// InverterRealtimeDataPath is the Fronius API URL-path for inverter real time data
-	InverterRealtimeDataPath = "/solar_api/v1/GetInverterRealtimeData.cgi?Scope=Device&DeviceId=1&DataCollection=CommonInverterData"
-	// get the current meter data
-	MeterRealtimeDataPath = "/solar_api/v1/GetMeterRealtimeData.cgi"
+	// ArchiveDataPath is the Fronius API URL-path for archive data.
+	// The requested channels are added as Channel parameters.
+	ArchiveDataPath = "/solar_api/v1/GetArchiveData.cgi?Scope=System&HumanReadable=false"
+	// InverterRealtimeDataPath is the Fronius API URL-path for inverter real time data.
+	// The placeholders are replaced with the inverter's device ID and the data collection.
+	InverterRealtimeDataPath = "/solar_api/v1/GetInverterRealtimeData.cgi?Scope=Device&DeviceId=%s&DataCollection=%s"
+	// InverterInfoPath is the Fronius API URL-path for static and status information of all inverters
+	InverterInfoPath = "/solar_api/v1/GetInverterInfo.cgi"
+	// ActiveDeviceInfoPath is the Fronius API URL-path for the list of active devices of all device classes
+	ActiveDeviceInfoPath = "/solar_api/v1/GetActiveDeviceInfo.cgi?DeviceClass=System"
+	// MeterRealtimeDataPath is the Fronius API URL-path for real time data of all smart meters
+	MeterRealtimeDataPath = "/solar_api/v1/GetMeterRealtimeData.cgi?Scope=System"
 )
 
+const (
+	// CommonInverterDataCollection is the inverter real time data collection with DC and cumulated AC values
+	CommonInverterDataCollection = "CommonInverterData"
+	// ThreePhaseInverterDataCollection is the inverter real time data collection with per-phase AC values of three-phase inverters
+	ThreePhaseInverterDataCollection = "3PInverterData"
+	// MinMaxInverterDataCollection is the inverter real time data collection with daily, yearly and overall extremes
+	MinMaxInverterDataCollection = "MinMaxInverterData"
+)
+
+// DefaultArchiveChannels are the archive channels requested if no channels are configured in ClientOptions.ArchiveChannels.
+var DefaultArchiveChannels = []string{"Voltage_DC_String_1", "Current_DC_String_1", "Voltage_DC_String_2", "Current_DC_String_2"}
+
 type (
 	symoPowerFlow struct {
 		Body struct {
@@ -26,6 +50,7 @@ type (
 		}
 	}
 	// SymoData holds the parsed data from the Symo API.
+	// Values that the device omits or reports as null, e.g. at night, are nil.
 	SymoData struct {
 		Inverters map[string]Inverter
 		Site      struct {
@@ -33,38 +58,81 @@ type (
 			MeterLocation string `json:"Meter_Location"`
 			// PowerGrid is the power supplied by the grid in Watt.
 			// A negative value means that excess power is provided back to the grid.
-			PowerGrid float64 `json:"P_Grid"`
+			PowerGrid *float64 `json:"P_Grid"`
 			// PowerLoad is the current load in Watt.
-			PowerLoad float64 `json:"P_Load"`
+			PowerLoad *float64 `json:"P_Load"`
 			// PowerAccu is the current power supplied from Accumulator in Watt.
-			PowerAccu float64 `json:"P_Akku"`
+			PowerAccu *float64 `json:"P_Akku"`
 			// PowerPhotovoltaic is the current power coming from Photovoltaic in Watt.
-			PowerPhotovoltaic float64 `json:"P_PV"`
+			PowerPhotovoltaic *float64 `json:"P_PV"`
 			// RelativeSelfConsumption indicates the ratio between the current power generated and the current load.
 			// When it reaches 100, the RelativeAutonomy declines, since the site can not produce enough energy and needs support from the grid.
-			// If the device returns null in PowerPhotovoltaic, this field becomes also 0!
-			RelativeSelfConsumption float64 `json:"rel_SelfConsumption"`
+			// If the device returns null in PowerPhotovoltaic, this field is also nil.
+			RelativeSelfConsumption *float64 `json:"rel_SelfConsumption"`
 			// RelativeAutonomy is the ratio of how autonomous the installation is.
 			// An autonomy of 100 means that the site is producing more energy than it is needed.
-			RelativeAutonomy float64 `json:"rel_Autonomy"`
+			RelativeAutonomy *float64 `json:"rel_Autonomy"`
 			// EnergyDay is the accumulated energy in Wh generated in this day so far.
 			// It is reset at the device's configured timezone at midnight.
-			EnergyDay float64 `json:"E_Day"`
+			EnergyDay *float64 `json:"E_Day"`
 			// EnergyYear is the accumulated energy in Wh generated in this year so far.
 			// It is reset at the device's configured timezone at midnight of 31st of December.
-			EnergyYear float64 `json:"E_Year"`
+			EnergyYear *float64 `json:"E_Year"`
 			// EnergyTotal is the accumulated energy in Wh generated in this site so far.
-			EnergyTotal float64 `json:"E_Total"`
+			EnergyTotal *float64 `json:"E_Total"`
 		}
 	}
 	// Inverter represents a power inverter installed at the Fronius Symo site.
 	Inverter struct {
-		DT          float64 `json:"DT"`
-		Power       float64 `json:"P"`
-		BatterySoC  float64 `json:"SOC"`
-		EnergyDay   float64 `json:"E_Day"`
-		EnergyYear  float64 `json:"E_Year"`
-		EnergyTotal float64 `json:"E_Total"`
+		DT          float64  `json:"DT"`
+		Power       *float64 `json:"P"`
+		BatterySoC  *float64 `json:"SOC"`
+		EnergyDay   *float64 `json:"E_Day"`
+		EnergyYear  *float64 `json:"E_Year"`
+		EnergyTotal *float64 `json:"E_Total"`
+	}
+
+	symoActiveDeviceInfo struct {
+		Body struct {
+			Data ActiveDeviceInfo `json:"Data"`
+		}
+	}
+	// ActiveDeviceInfo holds the devices attached to the Fronius Datamanager, grouped by device class and keyed by device ID.
+	ActiveDeviceInfo struct {
+		Inverters      map[string]ActiveDevice `json:"Inverter"`
+		Meters         map[string]ActiveDevice `json:"Meter"`
+		Storages       map[string]ActiveDevice `json:"Storage"`
+		Ohmpilots      map[string]ActiveDevice `json:"Ohmpilot"`
+		SensorCards    map[string]ActiveDevice `json:"SensorCard"`
+		StringControls map[string]ActiveDevice `json:"StringControl"`
+	}
+	// ActiveDevice represents a device that is currently attached to the Fronius Datamanager.
+	ActiveDevice struct {
+		// DT is the Fronius device type. Some device classes like meters and storages report -1.
+		DT     float64 `json:"DT"`
+		Serial string  `json:"Serial"`
+		// ChannelNames contains the names of the channels indexed by channel ID, only reported by sensor cards.
+		ChannelNames []string `json:"ChannelNames"`
+	}
+
+	symoInverterInfo struct {
+		Body struct {
+			Data map[string]InverterInfo `json:"Data"`
+		}
+	}
+	// InverterInfo holds the static and status information of an inverter.
+	InverterInfo struct {
+		// CustomName is the name of the inverter given by the installer.
+		CustomName string  `json:"CustomName"`
+		DT         float64 `json:"DT"`
+		// ErrorCode is the Fronius error code of the inverter, 0 if there is no error.
+		ErrorCode float64 `json:"ErrorCode"`
+		// PVPower is the installed peak power of the attached solar panels in Watt.
+		PVPower float64 `json:"PVPower"`
+		Show    float64 `json:"Show"`
+		// StatusCode is the operating state of the inverter, see State for a human-readable form.
+		StatusCode float64 `json:"StatusCode"`
+		UniqueID   string  `json:"UniqueID"`
 	}
 
 	symoInverterRealtime struct {
@@ -95,9 +163,57 @@ type (
 		TotalEnergyGenerated RealTimeDataPoint `json:"TOTAL_ENERGY"`
 	}
 
+	symoInverter3P struct {
+		Body struct {
+			Data SymoInverter3PData `json:"Data"`
+		}
+	}
+	// SymoInverter3PData holds the per-phase AC values of a three-phase inverter.
+	SymoInverter3PData struct {
+		//AC currents of phase 1 to 3 in ampere
+		AcCurrentL1 RealTimeDataPoint `json:"IAC_L1"`
+		AcCurrentL2 RealTimeDataPoint `json:"IAC_L2"`
+		AcCurrentL3 RealTimeDataPoint `json:"IAC_L3"`
+
+		//AC voltages between phase 1 to 3 and neutral in Volt
+		AcVoltageL1 RealTimeDataPoint `json:"UAC_L1"`
+		AcVoltageL2 RealTimeDataPoint `json:"UAC_L2"`
+		AcVoltageL3 RealTimeDataPoint `json:"UAC_L3"`
+	}
+
+	symoInverterMinMax struct {
+		Body struct {
+			Data SymoInverterMinMaxData `json:"Data"`
+		}
+	}
+	// SymoInverterMinMaxData holds the extreme values of an inverter for the current day, the current year and overall.
+	SymoInverterMinMaxData struct {
+		//Maximum AC power in Watt
+		DayPowerMax   RealTimeDataPoint `json:"DAY_PMAX"`
+		YearPowerMax  RealTimeDataPoint `json:"YEAR_PMAX"`
+		TotalPowerMax RealTimeDataPoint `json:"TOTAL_PMAX"`
+
+		//Maximum AC voltage in Volt
+		DayAcVoltageMax   RealTimeDataPoint `json:"DAY_UACMAX"`
+		YearAcVoltageMax  RealTimeDataPoint `json:"YEAR_UACMAX"`
+		TotalAcVoltageMax RealTimeDataPoint `json:"TOTAL_UACMAX"`
+
+		//Minimum AC voltage in Volt
+		DayAcVoltageMin   RealTimeDataPoint `json:"DAY_UACMIN"`
+		YearAcVoltageMin  RealTimeDataPoint `json:"YEAR_UACMIN"`
+		TotalAcVoltageMin RealTimeDataPoint `json:"TOTAL_UACMIN"`
+
+		//Maximum DC voltage in Volt
+		DayDcVoltageMax   RealTimeDataPoint `json:"DAY_UDCMAX"`
+		YearDcVoltageMax  RealTimeDataPoint `json:"YEAR_UDCMAX"`
+		TotalDcVoltageMax RealTimeDataPoint `json:"TOTAL_UDCMAX"`
+	}
+
+	// RealTimeDataPoint is a value of an inverter data collection.
 	RealTimeDataPoint struct {
-		Unit  string  `json:"Unit"`
-		Value float64 `json:"Value"`
+		Unit string `json:"Unit"`
+		// Value is nil if the device omits the value or reports it as null.
+		Value *float64 `json:"Value"`
 	}
 
 	symoMeter struct {
@@ -106,9 +222,59 @@ type (
 		}
 	}
 
+	// SymoMeterRealtimeData holds the real time data of a smart meter.
+	// Values that the device omits or reports as null are nil.
 	SymoMeterRealtimeData struct {
-		EnergyReal_WAC_Sum_Produced float64 `json:"EnergyReal_WAC_Sum_Produced"`
-		EnergyReal_WAC_Sum_Consumed float64 `json:"EnergyReal_WAC_Sum_Consumed"`
+		EnergyReal_WAC_Sum_Produced *float64 `json:"EnergyReal_WAC_Sum_Produced"`
+		EnergyReal_WAC_Sum_Consumed *float64 `json:"EnergyReal_WAC_Sum_Consumed"`
+
+		// AC voltages between phase and neutral in Volt
+		Voltage_AC_Phase_1 *float64 `json:"Voltage_AC_Phase_1"`
+		Voltage_AC_Phase_2 *float64 `json:"Voltage_AC_Phase_2"`
+		Voltage_AC_Phase_3 *float64 `json:"Voltage_AC_Phase_3"`
+
+		// AC currents in Ampere
+		Current_AC_Phase_1 *float64 `json:"Current_AC_Phase_1"`
+		Current_AC_Phase_2 *float64 `json:"Current_AC_Phase_2"`
+		Current_AC_Phase_3 *float64 `json:"Current_AC_Phase_3"`
+
+		// Active power in Watt. A positive value means that power is drawn from the grid
+		PowerReal_P_Phase_1 *float64 `json:"PowerReal_P_Phase_1"`
+		PowerReal_P_Phase_2 *float64 `json:"PowerReal_P_Phase_2"`
+		PowerReal_P_Phase_3 *float64 `json:"PowerReal_P_Phase_3"`
+
+		// Reactive power in VAr
+		PowerReactive_Q_Phase_1 *float64 `json:"PowerReactive_Q_Phase_1"`
+		PowerReactive_Q_Phase_2 *float64 `json:"PowerReactive_Q_Phase_2"`
+		PowerReactive_Q_Phase_3 *float64 `json:"PowerReactive_Q_Phase_3"`
+
+		// Apparent power in VA
+		PowerApparent_S_Phase_1 *float64 `json:"PowerApparent_S_Phase_1"`
+		PowerApparent_S_Phase_2 *float64 `json:"PowerApparent_S_Phase_2"`
+		PowerApparent_S_Phase_3 *float64 `json:"PowerApparent_S_Phase_3"`
+
+		// Power factor between -1 and 1
+		PowerFactor_Phase_1 *float64 `json:"PowerFactor_Phase_1"`
+		PowerFactor_Phase_2 *float64 `json:"PowerFactor_Phase_2"`
+		PowerFactor_Phase_3 *float64 `json:"PowerFactor_Phase_3"`
+
+		// Grid frequency averaged over all phases in Hz
+		Frequency_Phase_Average *float64 `json:"Frequency_Phase_Average"`
+
+		// Active energy registers per phase in Wh
+		EnergyReal_WAC_Phase_1_Consumed *float64 `json:"EnergyReal_WAC_Phase_1_Consumed"`
+		EnergyReal_WAC_Phase_2_Consumed *float64 `json:"EnergyReal_WAC_Phase_2_Consumed"`
+		EnergyReal_WAC_Phase_3_Consumed *float64 `json:"EnergyReal_WAC_Phase_3_Consumed"`
+		EnergyReal_WAC_Phase_1_Produced *float64 `json:"EnergyReal_WAC_Phase_1_Produced"`
+		EnergyReal_WAC_Phase_2_Produced *float64 `json:"EnergyReal_WAC_Phase_2_Produced"`
+		EnergyReal_WAC_Phase_3_Produced *float64 `json:"EnergyReal_WAC_Phase_3_Produced"`
+
+		// Reactive energy registers in VArh
+		EnergyReactive_VArAC_Sum_Consumed *float64 `json:"EnergyReactive_VArAC_Sum_Consumed"`
+		EnergyReactive_VArAC_Sum_Produced *float64 `json:"EnergyReactive_VArAC_Sum_Produced"`
+
+		// Installation point of the meter, see Location for a human-readable form
+		Meter_Location_Current float64 `json:"Meter_Location_Current"`
 	}
 
 	// SymoArchive holds the parsed archive data from Symo API
@@ -120,42 +286,99 @@ type (
 
 	// InverterArchive represents a power archive data with its channels
 	InverterArchive struct {
-		Data struct {
-			CurrentDCString1 Channel `json:"Current_DC_String_1"`
-			CurrentDCString2 Channel `json:"Current_DC_String_2"`
-			VoltageDCString1 Channel `json:"Voltage_DC_String_1"`
-			VoltageDCString2 Channel `json:"Voltage_DC_String_2"`
-		}
+		// Data contains the requested channels keyed by channel name, e.g. Voltage_DC_String_1.
+		// Channels that the device doesn't record are missing.
+		Data map[string]Channel
+		// Start is the point in time the value offsets of the channels are relative to.
+		Start time.Time
+		End   time.Time
 	}
 
 	// Channel represents the inverter channel data
 	Channel struct {
-		Unit   string
-		Values map[string]float64
+		Unit string
+		// Values are keyed by the offset in seconds from the start of the archive node.
+		// Values that the device reports as null are nil.
+		Values map[string]*float64
 	}
 
 	// SymoClient is a wrapper for making API requests against a Fronius Symo device.
+	// It is safe for concurrent use by multiple goroutines.
 	SymoClient struct {
-		request *http.Request
-		Options ClientOptions
+		httpClient *http.Client
+		Options    ClientOptions
+
+		mu sync.Mutex
+		// detectedLocation is the timezone of the device detected from the last archive response.
+		detectedLocation *time.Location
 	}
 	// ClientOptions holds some parameters for the SymoClient.
 	ClientOptions struct {
-		URL                     string
-		Headers                 http.Header
-		Timeout                 time.Duration
+		URL     string
+		Headers http.Header
+		Timeout time.Duration
+		// Username and Password are used to answer HTTP Digest authentication challenges of the device, if Username is set.
+		Username string
+		Password string
+		TLS      TLSOptions
+		// Location is the timezone configured in the device, which it uses to interpret the date range of archive requests.
+		// If nil, the timezone is detected from the archive responses.
+		Location                *time.Location
 		PowerFlowEnabled        bool
 		ArchiveEnabled          bool
 		InverterRealtimeEnabled bool
 		MeterRealtimeEnabled    bool
+		DeviceInfoEnabled       bool
+		InverterInfoEnabled     bool
+		StorageRealtimeEnabled  bool
+		OhmpilotRealtimeEnabled bool
+		SensorRealtimeEnabled   bool
+		// InverterDataCollections are the data collections requested from each inverter if InverterRealtimeEnabled is set.
+		// Defaults to CommonInverterDataCollection.
+		InverterDataCollections []string
+		// ArchiveChannels are the channels requested from the archive if ArchiveEnabled is set.
+		// Defaults to DefaultArchiveChannels.
+		ArchiveChannels []string
+		// Retries is the number of times a request that failed due to a transient error is repeated.
+		Retries int
+		// RetryBackoff is the delay before the first retry. It is doubled with each further retry.
+		RetryBackoff time.Duration
+		// OnRetry is called with the requested API path before a failed request is repeated, if set.
+		// It may be called concurrently.
+		OnRetry func(path string, attempt int, err error)
 	}
 )
 
 // NewSymoClient constructs a SymoClient ready to use for collecting metrics.
 func NewSymoClient(options ClientOptions) (*SymoClient, error) {
+	if len(options.InverterDataCollections) == 0 {
+		options.InverterDataCollections = []string{CommonInverterDataCollection}
+	}
+	for _, collection := range options.InverterDataCollections {
+		switch collection {
+		case CommonInverterDataCollection, ThreePhaseInverterDataCollection, MinMaxInverterDataCollection:
+		default:
+			return nil, fmt.Errorf("unsupported inverter data collection: %q", collection)
+		}
+	}
+	if len(options.ArchiveChannels) == 0 {
+		options.ArchiveChannels = DefaultArchiveChannels
+	}
+	tlsConfig, err := newTLSConfig(options.TLS)
+	if err != nil {
+		return nil, err
+	}
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	httpTransport.TLSClientConfig = tlsConfig
+
+	var transport http.RoundTripper = httpTransport
+	if options.Username != "" {
+		transport = newDigestTransport(options.Username, options.Password, transport)
+	}
 	return &SymoClient{
-		request: &http.Request{
-			Header: options.Headers,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   options.Timeout,
 		},
 		Options: options,
 	}, nil
@@ -163,103 +386,298 @@ func NewSymoClient(options ClientOptions) (*SymoClient, error) {
 
 // GetPowerFlowData returns the parsed data from the Symo device.
 func (c *SymoClient) GetPowerFlowData() (*SymoData, error) {
-	u, err := url.Parse(c.Options.URL + PowerDataPath)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetPowerFlowDataWithContext(context.Background())
+}
 
-	c.request.URL = u
-	client := http.DefaultClient
-	client.Timeout = c.Options.Timeout
-	response, err := client.Do(c.request)
-	if err != nil {
+// GetPowerFlowDataWithContext is like GetPowerFlowData, but aborts the request when the given context is done.
+func (c *SymoClient) GetPowerFlowDataWithContext(ctx context.Context) (*SymoData, error) {
+	p := symoPowerFlow{}
+	if err := c.fetch(ctx, PowerDataPath, &p); err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
-	p := symoPowerFlow{}
-	err = json.NewDecoder(response.Body).Decode(&p)
-	if err != nil {
+	return &p.Body.Data, nil
+}
+
+// GetActiveDeviceInfo returns the devices of all device classes that are currently attached to the Symo device.
+func (c *SymoClient) GetActiveDeviceInfo() (*ActiveDeviceInfo, error) {
+	return c.GetActiveDeviceInfoWithContext(context.Background())
+}
+
+// GetActiveDeviceInfoWithContext is like GetActiveDeviceInfo, but aborts the request when the given context is done.
+func (c *SymoClient) GetActiveDeviceInfoWithContext(ctx context.Context) (*ActiveDeviceInfo, error) {
+	p := symoActiveDeviceInfo{}
+	if err := c.fetch(ctx, ActiveDeviceInfoPath, &p); err != nil {
 		return nil, err
 	}
 	return &p.Body.Data, nil
 }
 
-// GetInverterRealtimeData returns the parsed data from the Symo device.
-func (c *SymoClient) GetInverterRealtimeData() (*SymoInverterRealtimeData, error) {
-	u, err := url.Parse(c.Options.URL + InverterRealtimeDataPath)
+// GetInverterIDs returns the sorted device IDs of all inverters that are currently active on the Symo device.
+func (c *SymoClient) GetInverterIDs() ([]string, error) {
+	return c.GetInverterIDsWithContext(context.Background())
+}
+
+// GetInverterIDsWithContext is like GetInverterIDs, but aborts the request when the given context is done.
+func (c *SymoClient) GetInverterIDsWithContext(ctx context.Context) ([]string, error) {
+	info, err := c.GetActiveDeviceInfoWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return info.InverterIDs(), nil
+}
 
-	c.request.URL = u
-	client := http.DefaultClient
-	client.Timeout = c.Options.Timeout
-	response, err := client.Do(c.request)
-	if err != nil {
+// InverterIDs returns the device IDs of the active inverters, sorted numerically.
+// Non-numeric IDs are sorted as strings after the numeric ones.
+func (i *ActiveDeviceInfo) InverterIDs() []string {
+	ids := make([]string, 0, len(i.Inverters))
+	for id := range i.Inverters {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(a, b int) bool {
+		numberA, errA := strconv.Atoi(ids[a])
+		numberB, errB := strconv.Atoi(ids[b])
+		switch {
+		case errA == nil && errB == nil:
+			return numberA < numberB
+		case errA == nil || errB == nil:
+			return errA == nil
+		}
+		return ids[a] < ids[b]
+	})
+	return ids
+}
+
+// GetInverterInfo returns the static and status information of all inverters keyed by device ID from the Symo device.
+func (c *SymoClient) GetInverterInfo() (map[string]InverterInfo, error) {
+	return c.GetInverterInfoWithContext(context.Background())
+}
+
+// GetInverterInfoWithContext is like GetInverterInfo, but aborts the request when the given context is done.
+func (c *SymoClient) GetInverterInfoWithContext(ctx context.Context) (map[string]InverterInfo, error) {
+	p := symoInverterInfo{}
+	if err := c.fetch(ctx, InverterInfoPath, &p); err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
+	for id, info := range p.Body.Data {
+		// The Datamanager encodes the custom name as HTML entities.
+		info.CustomName = html.UnescapeString(info.CustomName)
+		p.Body.Data[id] = info
+	}
+	return p.Body.Data, nil
+}
+
+// GetInverterRealtimeData returns the parsed data of the given inverter from the Symo device.
+func (c *SymoClient) GetInverterRealtimeData(inverterID string) (*SymoInverterRealtimeData, error) {
+	return c.GetInverterRealtimeDataWithContext(context.Background(), inverterID)
+}
+
+// GetInverterRealtimeDataWithContext is like GetInverterRealtimeData, but aborts the request when the given context is done.
+func (c *SymoClient) GetInverterRealtimeDataWithContext(ctx context.Context, inverterID string) (*SymoInverterRealtimeData, error) {
 	p := symoInverterRealtime{}
-	err = json.NewDecoder(response.Body).Decode(&p)
-	if err != nil {
+	if err := c.fetch(ctx, inverterRealtimeDataPath(inverterID, CommonInverterDataCollection), &p); err != nil {
 		return nil, err
 	}
 	return &p.Body.Data, nil
 }
 
-// GetMeterRealtimeData returns the parsed data from the Symo device.
-func (c *SymoClient) GetMeterRealtimeData() (*SymoMeterRealtimeData, error) {
-	u, err := url.Parse(c.Options.URL + MeterRealtimeDataPath)
-	if err != nil {
+// GetInverter3PData returns the parsed per-phase data of the given three-phase inverter from the Symo device.
+func (c *SymoClient) GetInverter3PData(inverterID string) (*SymoInverter3PData, error) {
+	return c.GetInverter3PDataWithContext(context.Background(), inverterID)
+}
+
+// GetInverter3PDataWithContext is like GetInverter3PData, but aborts the request when the given context is done.
+func (c *SymoClient) GetInverter3PDataWithContext(ctx context.Context, inverterID string) (*SymoInverter3PData, error) {
+	p := symoInverter3P{}
+	if err := c.fetch(ctx, inverterRealtimeDataPath(inverterID, ThreePhaseInverterDataCollection), &p); err != nil {
 		return nil, err
 	}
+	return &p.Body.Data, nil
+}
 
-	c.request.URL = u
-	client := http.DefaultClient
-	client.Timeout = c.Options.Timeout
-	response, err := client.Do(c.request)
-	if err != nil {
+// GetInverterMinMaxData returns the parsed extreme values of the given inverter from the Symo device.
+func (c *SymoClient) GetInverterMinMaxData(inverterID string) (*SymoInverterMinMaxData, error) {
+	return c.GetInverterMinMaxDataWithContext(context.Background(), inverterID)
+}
+
+// GetInverterMinMaxDataWithContext is like GetInverterMinMaxData, but aborts the request when the given context is done.
+func (c *SymoClient) GetInverterMinMaxDataWithContext(ctx context.Context, inverterID string) (*SymoInverterMinMaxData, error) {
+	p := symoInverterMinMax{}
+	if err := c.fetch(ctx, inverterRealtimeDataPath(inverterID, MinMaxInverterDataCollection), &p); err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
+	return &p.Body.Data, nil
+}
+
+func inverterRealtimeDataPath(inverterID, collection string) string {
+	return fmt.Sprintf(InverterRealtimeDataPath, url.QueryEscape(inverterID), collection)
+}
+
+// GetMeterRealtimeData returns the parsed data of all smart meters keyed by meter ID from the Symo device.
+func (c *SymoClient) GetMeterRealtimeData() (map[string]SymoMeterRealtimeData, error) {
+	return c.GetMeterRealtimeDataWithContext(context.Background())
+}
+
+// GetMeterRealtimeDataWithContext is like GetMeterRealtimeData, but aborts the request when the given context is done.
+func (c *SymoClient) GetMeterRealtimeDataWithContext(ctx context.Context) (map[string]SymoMeterRealtimeData, error) {
 	p := symoMeter{}
-	err = json.NewDecoder(response.Body).Decode(&p)
-	if err != nil {
+	if err := c.fetch(ctx, MeterRealtimeDataPath, &p); err != nil {
 		return nil, err
 	}
-	data := p.Body.Data["0"]
-	return &data, nil
+	return p.Body.Data, nil
 }
 
 // GetArchiveData returns the parsed data from the Symo device.
 func (c *SymoClient) GetArchiveData() (map[string]InverterArchive, error) {
-	u, err := url.Parse(c.Options.URL + ArchiveDataPath)
+	return c.GetArchiveDataWithContext(context.Background())
+}
+
+// GetArchiveDataWithContext is like GetArchiveData, but aborts the request when the given context is done.
+func (c *SymoClient) GetArchiveDataWithContext(ctx context.Context) (map[string]InverterArchive, error) {
+	now := time.Now()
+	return c.getArchive(ctx,
+		now.Truncate(5*time.Minute),
+		now.Add(5*time.Minute).Truncate(5*time.Minute))
+}
+
+// getArchive returns the configured archive channels between start and end from the Symo device.
+// If the timezone of the device wasn't known before and the response reveals a different one, the request is repeated.
+func (c *SymoClient) getArchive(ctx context.Context, start, end time.Time) (map[string]InverterArchive, error) {
+	location := c.DeviceLocation()
+	data, err := c.getArchiveIn(ctx, start.In(location), end.In(location))
 	if err != nil {
 		return nil, err
 	}
+	if detected := c.DeviceLocation(); !sameOffset(location, detected, start) || !sameOffset(location, detected, end) {
+		return c.getArchiveIn(ctx, start.In(detected), end.In(detected))
+	}
+	return data, nil
+}
 
-	c.request.URL = u
-	client := http.DefaultClient
-	client.Timeout = c.Options.Timeout
-	q := c.request.URL.Query()
+// getArchiveIn returns the configured archive channels between start and end from the Symo device.
+// The device interprets the dates in its own timezone, so start and end need to be in the device's location.
+func (c *SymoClient) getArchiveIn(ctx context.Context, start, end time.Time) (map[string]InverterArchive, error) {
+	u, err := url.Parse(ArchiveDataPath)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
 	q.Del("StartDate")
 	q.Del("EndDate")
+	for _, channel := range c.Options.ArchiveChannels {
+		q.Add("Channel", channel)
+	}
 
-	c.request.URL.RawQuery = fmt.Sprintf("%s&StartDate=%s&EndDate=%s",
+	path := fmt.Sprintf("%s?%s&StartDate=%s&EndDate=%s",
+		u.Path,
 		q.Encode(),
-		time.Now().Truncate(5*time.Minute).UTC().Local().Format(time.RFC3339),
-		time.Now().Add(5*time.Minute).Truncate(5*time.Minute).UTC().Local().Format(time.RFC3339))
+		start.Format(time.RFC3339),
+		end.Format(time.RFC3339))
 
-	response, err := client.Do(c.request)
+	p := symoArchive{}
+	if err := c.fetch(ctx, path, &p); err != nil {
+		return nil, err
+	}
+	c.detectLocation(p.Body.Data)
+	return p.Body.Data, nil
+}
 
+// fetchOnce requests the given API path from the Symo device and decodes the JSON response into v.
+// An *HTTPError is returned if the response isn't a successful JSON response,
+// and an *APIError if the device reports a non-zero status code in the response head.
+func (c *SymoClient) fetchOnce(ctx context.Context, path string, v interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Options.URL+path, nil)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if c.Options.Headers != nil {
+		request.Header = c.Options.Headers.Clone()
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
 	}
 	defer response.Body.Close()
-	p := symoArchive{}
-	err = json.NewDecoder(response.Body).Decode(&p)
+	payload, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if httpErr := newHTTPError(path, response, payload); httpErr != nil {
+		return httpErr
+	}
+
+	head := symoHead{}
+	if err := json.Unmarshal(payload, &head); err != nil {
+		return err
+	}
+	if status := head.Head.Status; status.Code != StatusOkay {
+		return &APIError{
+			Path:        path,
+			Code:        status.Code,
+			Reason:      status.Reason,
+			UserMessage: status.UserMessage,
+		}
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// Latest returns the non-null value with the highest offset, or false if the channel has no such values.
+func (c Channel) Latest() (float64, bool) {
+	latestOffset, latest, found := 0, 0.0, false
+	for key, value := range c.Values {
+		offset, err := strconv.Atoi(key)
+		if err != nil || value == nil {
+			continue
+		}
+		if !found || offset > latestOffset {
+			latestOffset, latest, found = offset, *value, true
+		}
+	}
+	return latest, found
+}
+
+// State returns the human-readable operating state of the inverter's StatusCode.
+func (i InverterInfo) State() string {
+	switch code := int(i.StatusCode); {
+	case code >= 0 && code <= 6:
+		return "Startup"
+	case code == 7:
+		return "Running"
+	case code == 8:
+		return "Standby"
+	case code == 9:
+		return "Bootloading"
+	case code == 10:
+		return "Error"
+	case code == 11:
+		return "Idle"
+	case code == 12:
+		return "Ready"
+	case code == 13:
+		return "Sleeping"
+	case code == 255:
+		return "Unknown"
+	default:
+		return "Invalid"
+	}
+}
+
+// Location returns the human-readable installation point of the meter's Meter_Location_Current.
+func (m SymoMeterRealtimeData) Location() string {
+	switch location := int(m.Meter_Location_Current); {
+	case location == 0:
+		return "grid"
+	case location == 1:
+		return "load"
+	case location == 3:
+		return "external_generator"
+	case location >= 256 && location <= 511:
+		return "subload"
+	case location >= 512 && location <= 768:
+		return "ev_charger"
+	case location >= 769 && location <= 1023:
+		return "storage"
+	default:
+		return "unknown"
 	}
-	return p.Body.Data, nil
 }
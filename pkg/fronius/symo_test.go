@@ -1,15 +1,24 @@
 package fronius
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// floatPtr returns a pointer to the given value, for comparing optional values.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
 func Test_Symo_GetPowerFlowData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		payload, err := os.ReadFile("testdata/example_1.json")
@@ -24,21 +33,22 @@ func Test_Symo_GetPowerFlowData_GivenUrl_WhenRequestData_ThenParseStruct(t *test
 
 	p, err := c.GetPowerFlowData()
 	assert.NoError(t, err)
-	assert.Equal(t, 611.39999999999998, p.Site.PowerGrid)
-	assert.Equal(t, -611.39999999999998, p.Site.PowerLoad)
-	assert.Equal(t, float64(0), p.Site.PowerPhotovoltaic)
-	assert.Equal(t, float64(0), p.Site.PowerAccu)
-	assert.Equal(t, float64(0), p.Site.RelativeSelfConsumption)
-	assert.Equal(t, 46.564, p.Site.RelativeAutonomy)
-	assert.Equal(t, float64(22997), p.Site.EnergyDay)
-	assert.Equal(t, float64(43059100), p.Site.EnergyTotal)
-	assert.Equal(t, 3525577.75, p.Site.EnergyYear)
+	assert.Equal(t, floatPtr(611.39999999999998), p.Site.PowerGrid)
+	assert.Equal(t, floatPtr(-611.39999999999998), p.Site.PowerLoad)
+	assert.Nil(t, p.Site.PowerPhotovoltaic)
+	assert.Nil(t, p.Site.PowerAccu)
+	assert.Nil(t, p.Site.RelativeSelfConsumption)
+	assert.Equal(t, floatPtr(46.564), p.Site.RelativeAutonomy)
+	assert.Equal(t, floatPtr(22997), p.Site.EnergyDay)
+	assert.Equal(t, floatPtr(43059100), p.Site.EnergyTotal)
+	assert.Equal(t, floatPtr(3525577.75), p.Site.EnergyYear)
 
-	assert.Equal(t, 34.5, p.Inverters["1"].BatterySoC)
+	assert.Equal(t, floatPtr(34.5), p.Inverters["1"].BatterySoC)
 }
 
 func Test_Symo_GetArchiveData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, DefaultArchiveChannels, req.URL.Query()["Channel"])
 		payload, err := os.ReadFile("testdata/test_archive_data.json")
 		require.NoError(t, err)
 		_, _ = rw.Write(payload)
@@ -54,14 +64,190 @@ func Test_Symo_GetArchiveData_GivenUrl_WhenRequestData_ThenParseStruct(t *testin
 
 	p, err := c.GetArchiveData()
 	assert.NoError(t, err)
-	assert.Equal(t, float64(13), p["inverter/1"].Data.CurrentDCString1.Values["0"])
-	assert.Equal(t, float64(15.92), p["inverter/1"].Data.CurrentDCString2.Values["0"])
-	assert.Equal(t, float64(425.6), p["inverter/1"].Data.VoltageDCString1.Values["0"])
-	assert.Equal(t, float64(408.90000000000003), p["inverter/1"].Data.VoltageDCString2.Values["0"])
+	assert.Equal(t, floatPtr(13), p["inverter/1"].Data["Current_DC_String_1"].Values["0"])
+	assert.Equal(t, floatPtr(15.92), p["inverter/1"].Data["Current_DC_String_2"].Values["0"])
+	assert.Equal(t, floatPtr(425.6), p["inverter/1"].Data["Voltage_DC_String_1"].Values["0"])
+	assert.Equal(t, floatPtr(408.90000000000003), p["inverter/1"].Data["Voltage_DC_String_2"].Values["0"])
+	assert.Equal(t, "A", p["inverter/1"].Data["Current_DC_String_1"].Unit)
+}
+
+func Test_Symo_GetArchiveData_GivenChannels_WhenRequestData_ThenRequestAndParseChannels(t *testing.T) {
+	channels := []string{"Temperature_Powerstage", "EnergyReal_WAC_Sum_Produced", "PowerReal_PAC_Sum", "Voltage_DC_String_3"}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, channels, req.URL.Query()["Channel"])
+		assert.Equal(t, "System", req.URL.Query().Get("Scope"))
+		payload, err := os.ReadFile("testdata/archive_channels.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:             server.URL,
+		ArchiveEnabled:  true,
+		ArchiveChannels: channels,
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetArchiveData()
+	assert.NoError(t, err)
+	require.Len(t, p, 2)
+	data := p["inverter/1"].Data
+	assert.Len(t, data, 4)
+	assert.Equal(t, "°C", data["Temperature_Powerstage"].Unit)
+	assert.Equal(t, floatPtr(42.5), data["Temperature_Powerstage"].Values["0"])
+	assert.Equal(t, "Wh", data["EnergyReal_WAC_Sum_Produced"].Unit)
+	assert.Equal(t, "W", data["PowerReal_PAC_Sum"].Unit)
+	assert.Equal(t, floatPtr(512.3), data["Voltage_DC_String_3"].Values["0"])
+	assert.NotContains(t, p["inverter/2"].Data, "Voltage_DC_String_3")
+}
+
+func Test_Channel_Latest(t *testing.T) {
+	tests := map[string]struct {
+		values        map[string]*float64
+		expected      float64
+		expectedFound bool
+	}{
+		"GivenNoValues_ThenReturnNotFound": {},
+		"GivenSingleValue_ThenReturnIt": {
+			values:        map[string]*float64{"0": floatPtr(13)},
+			expected:      13,
+			expectedFound: true,
+		},
+		"GivenMultipleValues_ThenReturnHighestOffset": {
+			values:        map[string]*float64{"0": floatPtr(1), "600": floatPtr(3), "300": floatPtr(2)},
+			expected:      3,
+			expectedFound: true,
+		},
+		"GivenNullLatestValue_ThenReturnLatestNonNullValue": {
+			values:        map[string]*float64{"0": floatPtr(1), "300": nil},
+			expected:      1,
+			expectedFound: true,
+		},
+		"GivenOnlyNullValues_ThenReturnNotFound": {
+			values: map[string]*float64{"0": nil},
+		},
+		"GivenZeroValue_ThenReturnIt": {
+			values:        map[string]*float64{"0": floatPtr(0)},
+			expected:      0,
+			expectedFound: true,
+		},
+		"GivenInvalidOffset_ThenIgnoreIt": {
+			values:        map[string]*float64{"0": floatPtr(1), "invalid": floatPtr(2)},
+			expected:      1,
+			expectedFound: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, found := Channel{Values: tt.values}.Latest()
+			assert.Equal(t, tt.expected, value)
+			assert.Equal(t, tt.expectedFound, found)
+		})
+	}
+}
+
+func Test_Symo_GetActiveDeviceInfo_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "System", req.URL.Query().Get("DeviceClass"))
+		payload, err := os.ReadFile("testdata/activedeviceinfo.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:               server.URL,
+		DeviceInfoEnabled: true,
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetActiveDeviceInfo()
+	assert.NoError(t, err)
+	assert.Len(t, p.Inverters, 2)
+	assert.Equal(t, float64(123), p.Inverters["1"].DT)
+	assert.Equal(t, "28136344", p.Inverters["1"].Serial)
+	assert.Equal(t, float64(-1), p.Meters["0"].DT)
+	assert.Equal(t, "16220115", p.Meters["0"].Serial)
+	assert.Equal(t, "P030T020Z2009160", p.Storages["0"].Serial)
+	assert.Equal(t, "28136300", p.Ohmpilots["0"].Serial)
+	assert.Equal(t, "Irradiance", p.SensorCards["1"].ChannelNames[2])
+	assert.Empty(t, p.StringControls)
+}
+
+func Test_Symo_GetInverterIDs_GivenUrl_WhenRequestData_ThenReturnSortedIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		payload, err := os.ReadFile("testdata/activedeviceinfo.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                     server.URL,
+		InverterRealtimeEnabled: true,
+	})
+	require.NoError(t, err)
+
+	ids, err := c.GetInverterIDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, ids)
+}
+
+func Test_ActiveDeviceInfo_InverterIDs_GivenMultiDigitIDs_ThenSortNumerically(t *testing.T) {
+	info := ActiveDeviceInfo{Inverters: map[string]ActiveDevice{
+		"10": {}, "2": {}, "1": {}, "inverter": {}, "100": {}, "a": {},
+	}}
+	assert.Equal(t, []string{"1", "2", "10", "100", "a", "inverter"}, info.InverterIDs())
+}
+
+func Test_Symo_GetInverterInfo_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		payload, err := os.ReadFile("testdata/inverterinfo.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                 server.URL,
+		InverterInfoEnabled: true,
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetInverterInfo()
+	assert.NoError(t, err)
+	assert.Len(t, p, 2)
+	assert.Equal(t, "Symo East", p["1"].CustomName)
+	assert.Equal(t, float64(8500), p["1"].PVPower)
+	assert.Equal(t, float64(7), p["1"].StatusCode)
+	assert.Equal(t, "Running", p["1"].State())
+	assert.Equal(t, "Symo West", p["2"].CustomName)
+	assert.Equal(t, float64(567), p["2"].ErrorCode)
+	assert.Equal(t, "Error", p["2"].State())
+}
+
+func Test_InverterInfo_State(t *testing.T) {
+	tests := map[string]struct {
+		statusCode float64
+		expected   string
+	}{
+		"GivenCode0_ThenStartup":         {statusCode: 0, expected: "Startup"},
+		"GivenCode6_ThenStartup":         {statusCode: 6, expected: "Startup"},
+		"GivenCode7_ThenRunning":         {statusCode: 7, expected: "Running"},
+		"GivenCode8_ThenStandby":         {statusCode: 8, expected: "Standby"},
+		"GivenCode10_ThenError":          {statusCode: 10, expected: "Error"},
+		"GivenCode13_ThenSleeping":       {statusCode: 13, expected: "Sleeping"},
+		"GivenCode255_ThenUnknown":       {statusCode: 255, expected: "Unknown"},
+		"GivenUndefinedCode_ThenInvalid": {statusCode: 42, expected: "Invalid"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, InverterInfo{StatusCode: tt.statusCode}.State())
+		})
+	}
 }
 
 func Test_Symo_GetInverterRealtimeData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "2", req.URL.Query().Get("DeviceId"))
+		assert.Equal(t, "CommonInverterData", req.URL.Query().Get("DataCollection"))
 		payload, err := os.ReadFile("testdata/realtimedata.json")
 		require.NoError(t, err)
 		_, _ = rw.Write(payload)
@@ -75,37 +261,127 @@ func Test_Symo_GetInverterRealtimeData_GivenUrl_WhenRequestData_ThenParseStruct(
 	})
 	require.NoError(t, err)
 
-	p, err := c.GetInverterRealtimeData()
+	p, err := c.GetInverterRealtimeData("2")
 	assert.NoError(t, err)
 
 	//current
-	assert.Equal(t, float64(0.021116470918059349), p.DcCurrentMPPT1.Value)
-	assert.Equal(t, float64(0.01560344360768795), p.DcCurrentMPPT2.Value)
-	assert.Equal(t, float64(0), p.DcCurrentMPPT3.Value)
-	assert.Equal(t, float64(0), p.DcCurrentMPPT4.Value)
+	assert.Equal(t, floatPtr(0.021116470918059349), p.DcCurrentMPPT1.Value)
+	assert.Equal(t, floatPtr(0.01560344360768795), p.DcCurrentMPPT2.Value)
+	assert.Nil(t, p.DcCurrentMPPT3.Value)
+	assert.Nil(t, p.DcCurrentMPPT4.Value)
 	assert.Equal(t, "A", p.DcCurrentMPPT1.Unit)
 	assert.Equal(t, "A", p.DcCurrentMPPT2.Unit)
 	assert.Equal(t, "A", p.DcCurrentMPPT3.Unit)
 	assert.Equal(t, "A", p.DcCurrentMPPT4.Unit)
 
 	//voltage
-	assert.Equal(t, float64(44.587142944335938), p.DcVoltageMPPT1.Value)
-	assert.Equal(t, float64(72.194984436035156), p.DcVoltageMPPT2.Value)
-	assert.Equal(t, float64(0), p.DcVoltageMPPT3.Value)
-	assert.Equal(t, float64(0), p.DcVoltageMPPT4.Value)
+	assert.Equal(t, floatPtr(44.587142944335938), p.DcVoltageMPPT1.Value)
+	assert.Equal(t, floatPtr(72.194984436035156), p.DcVoltageMPPT2.Value)
+	assert.Nil(t, p.DcVoltageMPPT3.Value)
+	assert.Nil(t, p.DcVoltageMPPT4.Value)
 	assert.Equal(t, "V", p.DcVoltageMPPT1.Unit)
 	assert.Equal(t, "V", p.DcVoltageMPPT2.Unit)
 	assert.Equal(t, "V", p.DcVoltageMPPT3.Unit)
 	assert.Equal(t, "V", p.DcVoltageMPPT4.Unit)
 
 	//AC frequency
-	assert.Equal(t, float64(50.029872894287109), p.AcFrequency.Value)
+	assert.Equal(t, floatPtr(50.029872894287109), p.AcFrequency.Value)
 
 	//AC power
-	assert.Equal(t, float64(253.71487426757812), p.AcPower.Value)
+	assert.Equal(t, floatPtr(253.71487426757812), p.AcPower.Value)
 
 	//Total energy generated
-	assert.Equal(t, float64(1392623.8052777778), p.TotalEnergyGenerated.Value)
+	assert.Equal(t, floatPtr(1392623.8052777778), p.TotalEnergyGenerated.Value)
+}
+
+func Test_Symo_GetInverter3PData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "1", req.URL.Query().Get("DeviceId"))
+		assert.Equal(t, "3PInverterData", req.URL.Query().Get("DataCollection"))
+		payload, err := os.ReadFile("testdata/3pinverterdata.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                     server.URL,
+		InverterRealtimeEnabled: true,
+		InverterDataCollections: []string{ThreePhaseInverterDataCollection},
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetInverter3PData("1")
+	assert.NoError(t, err)
+	assert.Equal(t, floatPtr(3.9), p.AcCurrentL1.Value)
+	assert.Equal(t, floatPtr(3.8), p.AcCurrentL2.Value)
+	assert.Equal(t, floatPtr(3.91), p.AcCurrentL3.Value)
+	assert.Equal(t, "A", p.AcCurrentL1.Unit)
+	assert.Equal(t, floatPtr(232.1), p.AcVoltageL1.Value)
+	assert.Equal(t, floatPtr(233.9), p.AcVoltageL2.Value)
+	assert.Equal(t, floatPtr(231.4), p.AcVoltageL3.Value)
+	assert.Equal(t, "V", p.AcVoltageL1.Unit)
+}
+
+func Test_Symo_GetInverterMinMaxData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "1", req.URL.Query().Get("DeviceId"))
+		assert.Equal(t, "MinMaxInverterData", req.URL.Query().Get("DataCollection"))
+		payload, err := os.ReadFile("testdata/minmaxinverterdata.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+
+	c, err := NewSymoClient(ClientOptions{
+		URL:                     server.URL,
+		InverterRealtimeEnabled: true,
+		InverterDataCollections: []string{MinMaxInverterDataCollection},
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetInverterMinMaxData("1")
+	assert.NoError(t, err)
+	assert.Equal(t, floatPtr(4914), p.DayPowerMax.Value)
+	assert.Equal(t, floatPtr(8243), p.YearPowerMax.Value)
+	assert.Equal(t, floatPtr(8476), p.TotalPowerMax.Value)
+	assert.Equal(t, "W", p.DayPowerMax.Unit)
+	assert.Equal(t, floatPtr(243.4), p.DayAcVoltageMax.Value)
+	assert.Equal(t, floatPtr(226.1), p.DayAcVoltageMin.Value)
+	assert.Equal(t, floatPtr(252.7), p.TotalAcVoltageMax.Value)
+	assert.Equal(t, floatPtr(0), p.TotalAcVoltageMin.Value)
+	assert.Equal(t, floatPtr(729.5), p.DayDcVoltageMax.Value)
+	assert.Equal(t, floatPtr(815.8), p.YearDcVoltageMax.Value)
+	assert.Equal(t, floatPtr(842.4), p.TotalDcVoltageMax.Value)
+}
+
+func Test_NewSymoClient(t *testing.T) {
+	tests := map[string]struct {
+		collections   []string
+		expected      []string
+		expectedError string
+	}{
+		"GivenNoCollections_ThenDefaultToCommonInverterData": {
+			expected: []string{"CommonInverterData"},
+		},
+		"GivenSupportedCollections_ThenKeepThem": {
+			collections: []string{"CommonInverterData", "3PInverterData", "MinMaxInverterData"},
+			expected:    []string{"CommonInverterData", "3PInverterData", "MinMaxInverterData"},
+		},
+		"GivenUnsupportedCollection_ThenReturnError": {
+			collections:   []string{"CumulationInverterData"},
+			expectedError: `unsupported inverter data collection: "CumulationInverterData"`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewSymoClient(ClientOptions{InverterDataCollections: tt.collections})
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, c.Options.InverterDataCollections)
+		})
+	}
 }
 
 func Test_Symo_GetMeterRealtimeData_GivenUrl_WhenRequestData_ThenParseStruct(t *testing.T) {
@@ -121,9 +397,278 @@ func Test_Symo_GetMeterRealtimeData_GivenUrl_WhenRequestData_ThenParseStruct(t *
 	})
 	require.NoError(t, err)
 
-	p, err := c.GetMeterRealtimeData()
+	meters, err := c.GetMeterRealtimeData()
 	assert.NoError(t, err)
-	assert.NotNil(t, p)
-	assert.Equal(t, float64(12345.67), p.EnergyReal_WAC_Sum_Produced)
-	assert.Equal(t, float64(7654.32), p.EnergyReal_WAC_Sum_Consumed)
+	require.Len(t, meters, 2)
+
+	p := meters["0"]
+	assert.Equal(t, "grid", p.Location())
+	assert.Equal(t, floatPtr(12345.67), p.EnergyReal_WAC_Sum_Produced)
+	assert.Equal(t, floatPtr(7654.32), p.EnergyReal_WAC_Sum_Consumed)
+
+	assert.Equal(t, floatPtr(233.8), p.Voltage_AC_Phase_1)
+	assert.Equal(t, floatPtr(235.2), p.Voltage_AC_Phase_2)
+	assert.Equal(t, floatPtr(234.4), p.Voltage_AC_Phase_3)
+	assert.Equal(t, floatPtr(1.145), p.Current_AC_Phase_1)
+	assert.Equal(t, floatPtr(2.085), p.Current_AC_Phase_2)
+	assert.Equal(t, floatPtr(0.998), p.Current_AC_Phase_3)
+	assert.Equal(t, floatPtr(250.18), p.PowerReal_P_Phase_1)
+	assert.Equal(t, floatPtr(-95.3), p.PowerReactive_Q_Phase_2)
+	assert.Equal(t, floatPtr(233.64), p.PowerApparent_S_Phase_3)
+	assert.Equal(t, floatPtr(-0.97), p.PowerFactor_Phase_3)
+	assert.Equal(t, floatPtr(49.98), p.Frequency_Phase_Average)
+
+	assert.Equal(t, floatPtr(2551.44), p.EnergyReal_WAC_Phase_1_Consumed)
+	assert.Equal(t, floatPtr(4115.23), p.EnergyReal_WAC_Phase_3_Produced)
+	assert.Equal(t, floatPtr(86669), p.EnergyReactive_VArAC_Sum_Consumed)
+	assert.Equal(t, floatPtr(1568034), p.EnergyReactive_VArAC_Sum_Produced)
+
+	assert.Equal(t, float64(256), meters["1"].Meter_Location_Current)
+	assert.Equal(t, "subload", meters["1"].Location())
+	assert.Equal(t, floatPtr(3210.5), meters["1"].EnergyReal_WAC_Sum_Consumed)
+	assert.Nil(t, meters["1"].EnergyReal_WAC_Phase_1_Consumed, "the subload meter has no per-phase registers")
+	assert.Nil(t, meters["1"].EnergyReactive_VArAC_Sum_Produced, "the subload meter has no reactive registers")
+}
+
+func Test_SymoMeterRealtimeData_Location(t *testing.T) {
+	tests := map[string]struct {
+		location float64
+		expected string
+	}{
+		"GivenLocation0_ThenGrid":              {location: 0, expected: "grid"},
+		"GivenLocation1_ThenLoad":              {location: 1, expected: "load"},
+		"GivenLocation3_ThenExternalGenerator": {location: 3, expected: "external_generator"},
+		"GivenLocation256_ThenSubload":         {location: 256, expected: "subload"},
+		"GivenLocation511_ThenSubload":         {location: 511, expected: "subload"},
+		"GivenLocation600_ThenEVCharger":       {location: 600, expected: "ev_charger"},
+		"GivenLocation800_ThenStorage":         {location: 800, expected: "storage"},
+		"GivenLocation2_ThenUnknown":           {location: 2, expected: "unknown"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SymoMeterRealtimeData{Meter_Location_Current: tt.location}.Location())
+		})
+	}
+}
+
+func Test_Symo_GivenParallelRequests_WhenRequestData_ThenDoNotInterfere(t *testing.T) {
+	fixtures := map[string]string{
+		"/solar_api/v1/GetPowerFlowRealtimeData.fcgi": "testdata/example_1.json",
+		"/solar_api/v1/GetArchiveData.cgi":            "testdata/test_archive_data.json",
+		"/solar_api/v1/GetInverterRealtimeData.cgi":   "testdata/realtimedata.json",
+		"/solar_api/v1/GetMeterRealtimeData.cgi":      "testdata/meterrealtimedata.json",
+		"/solar_api/v1/GetActiveDeviceInfo.cgi":       "testdata/activedeviceinfo.json",
+		"/solar_api/v1/GetInverterInfo.cgi":           "testdata/inverterinfo.json",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "value", req.Header.Get("X-Test"))
+		payload, err := os.ReadFile(fixtures[req.URL.Path])
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+	defer server.Close()
+
+	headers := http.Header{}
+	headers.Set("X-Test", "value")
+	c, err := NewSymoClient(ClientOptions{
+		URL:     server.URL,
+		Headers: headers,
+		Timeout: 3 * time.Second,
+	})
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(6)
+		go func() {
+			defer wg.Done()
+			p, err := c.GetPowerFlowData()
+			assert.NoError(t, err)
+			assert.Equal(t, floatPtr(34.5), p.Inverters["1"].BatterySoC)
+		}()
+		go func() {
+			defer wg.Done()
+			p, err := c.GetArchiveData()
+			assert.NoError(t, err)
+			assert.Equal(t, floatPtr(13), p["inverter/1"].Data["Current_DC_String_1"].Values["0"])
+		}()
+		go func() {
+			defer wg.Done()
+			p, err := c.GetInverterRealtimeData("1")
+			assert.NoError(t, err)
+			assert.Equal(t, floatPtr(253.71487426757812), p.AcPower.Value)
+		}()
+		go func() {
+			defer wg.Done()
+			p, err := c.GetMeterRealtimeData()
+			assert.NoError(t, err)
+			assert.Len(t, p, 2)
+		}()
+		go func() {
+			defer wg.Done()
+			p, err := c.GetInverterIDs()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"1", "2"}, p)
+		}()
+		go func() {
+			defer wg.Done()
+			p, err := c.GetInverterInfo()
+			assert.NoError(t, err)
+			assert.Equal(t, "Symo East", p["1"].CustomName)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, time.Duration(0), http.DefaultClient.Timeout, "the default client must not be modified")
+	assert.Equal(t, "value", headers.Get("X-Test"))
+}
+
+func Test_Symo_GivenCancelledContext_WhenRequestData_ThenAbortRequest(t *testing.T) {
+	requested := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(requested)
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	c, err := NewSymoClient(ClientOptions{
+		URL: server.URL,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-requested
+		cancel()
+	}()
+	p, err := c.GetPowerFlowDataWithContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, p)
+}
+
+func Test_Symo_GivenNonZeroStatusCode_WhenRequestData_ThenReturnAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{
+			"Body": {"Data": {}},
+			"Head": {
+				"RequestArguments": {"DataCollection": "CommonInverterData", "DeviceId": "1", "Scope": "Device"},
+				"Status": {"Code": 8, "Reason": "Transfer timeout.", "UserMessage": ""},
+				"Timestamp": "2024-09-05T22:37:52+00:00"
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	c, err := NewSymoClient(ClientOptions{
+		URL: server.URL,
+	})
+	require.NoError(t, err)
+
+	p, err := c.GetInverterRealtimeData("1")
+	assert.Nil(t, p)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, StatusLNRequestTimeout, apiErr.Code)
+	assert.Equal(t, "Transfer timeout.", apiErr.Reason)
+	assert.EqualError(t, err, "solar api returned status 8 (LNRequestTimeout) for /solar_api/v1/GetInverterRealtimeData.cgi?Scope=Device&DeviceId=1&DataCollection=CommonInverterData: Transfer timeout.")
+}
+
+func Test_StatusCode_String(t *testing.T) {
+	tests := map[string]struct {
+		code     StatusCode
+		expected string
+	}{
+		"GivenCode0_ThenOkay":                {code: 0, expected: "Okay"},
+		"GivenCode8_ThenLNRequestTimeout":    {code: 8, expected: "LNRequestTimeout"},
+		"GivenCode12_ThenDeviceNotAvailable": {code: 12, expected: "DeviceNotAvailable"},
+		"GivenCode255_ThenUnknownError":      {code: 255, expected: "UnknownError"},
+		"GivenUndefinedCode_ThenInvalid":     {code: 42, expected: "Invalid"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.code.String())
+		})
+	}
+}
+
+func Test_Symo_GivenUnexpectedResponse_WhenRequestData_ThenReturnHTTPError(t *testing.T) {
+	tests := map[string]struct {
+		statusCode    int
+		contentType   string
+		body          string
+		expectedBody  string
+		expectedError string
+	}{
+		"GivenUnauthorizedFromProxy_ThenReturnStatusAndBody": {
+			statusCode:    http.StatusUnauthorized,
+			contentType:   "text/html; charset=utf-8",
+			body:          "<html><body>401 Authorization Required</body></html>\n",
+			expectedBody:  "<html><body>401 Authorization Required</body></html>",
+			expectedError: `unexpected http status 401 for /solar_api/v1/GetPowerFlowRealtimeData.fcgi: "<html><body>401 Authorization Required</body></html>"`,
+		},
+		"GivenInternalServerError_ThenReturnStatus": {
+			statusCode:    http.StatusInternalServerError,
+			contentType:   "application/json",
+			body:          "{}",
+			expectedBody:  "{}",
+			expectedError: `unexpected http status 500 for /solar_api/v1/GetPowerFlowRealtimeData.fcgi: "{}"`,
+		},
+		"GivenHTMLContentType_ThenReturnContentType": {
+			statusCode:    http.StatusOK,
+			contentType:   "text/html",
+			body:          "<html></html>",
+			expectedBody:  "<html></html>",
+			expectedError: `unexpected content type "text/html" for /solar_api/v1/GetPowerFlowRealtimeData.fcgi: "<html></html>"`,
+		},
+		"GivenLongBody_ThenTruncateBody": {
+			statusCode:   http.StatusBadGateway,
+			contentType:  "text/plain",
+			body:         strings.Repeat("x", 1000),
+			expectedBody: strings.Repeat("x", maxErrorBodySnippet),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", tt.contentType)
+				rw.WriteHeader(tt.statusCode)
+				_, _ = rw.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c, err := NewSymoClient(ClientOptions{
+				URL: server.URL,
+			})
+			require.NoError(t, err)
+
+			p, err := c.GetPowerFlowData()
+			assert.Nil(t, p)
+			var httpErr *HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, tt.statusCode, httpErr.StatusCode)
+			assert.Equal(t, tt.expectedBody, httpErr.Body)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func Test_isJSONContentType(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		expected    bool
+	}{
+		"GivenEmpty_ThenAccept":                {contentType: "", expected: true},
+		"GivenApplicationJSON_ThenAccept":      {contentType: "application/json", expected: true},
+		"GivenTextJavascript_ThenAccept":       {contentType: "text/javascript; charset=utf-8", expected: true},
+		"GivenTextPlain_ThenAccept":            {contentType: "text/plain; charset=utf-8", expected: true},
+		"GivenTextHTML_ThenReject":             {contentType: "text/html; charset=iso-8859-1", expected: false},
+		"GivenMalformedContentType_ThenReject": {contentType: "/;", expected: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isJSONContentType(tt.contentType))
+		})
+	}
 }
@@ -0,0 +1,154 @@
+package fronius
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSServer returns a HTTPS server serving the power flow fixture and the path to a PEM file with its certificate.
+func newTLSServer(t *testing.T, config *tls.Config) (*httptest.Server, string) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		payload, err := os.ReadFile("testdata/example_1.json")
+		require.NoError(t, err)
+		_, _ = rw.Write(payload)
+	}))
+	server.TLS = config
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+	return server, caFile
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+// newClientCertificate generates a self-signed client certificate and returns the certificate and the paths to its PEM files.
+func newClientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fronius-exporter"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDer)
+	return cert, certFile, keyFile
+}
+
+func Test_Symo_GivenTLSServer_WhenRequestData_ThenApplyTLSOptions(t *testing.T) {
+	server, caFile := newTLSServer(t, nil)
+
+	tests := map[string]struct {
+		options       TLSOptions
+		expectedError bool
+	}{
+		"GivenNoOptions_ThenRejectUnknownCA": {
+			expectedError: true,
+		},
+		"GivenCAFile_ThenTrustServer": {
+			options: TLSOptions{CAFile: caFile},
+		},
+		"GivenServerNameInCertificate_ThenTrustServer": {
+			options: TLSOptions{CAFile: caFile, ServerName: "example.com"},
+		},
+		"GivenServerNameNotInCertificate_ThenRejectServer": {
+			options:       TLSOptions{CAFile: caFile, ServerName: "symo.example.org"},
+			expectedError: true,
+		},
+		"GivenInsecureSkipVerify_ThenSkipVerification": {
+			options: TLSOptions{InsecureSkipVerify: true},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewSymoClient(ClientOptions{
+				URL: server.URL,
+				TLS: tt.options,
+			})
+			require.NoError(t, err)
+
+			p, err := c.GetPowerFlowData()
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, floatPtr(34.5), p.Inverters["1"].BatterySoC)
+		})
+	}
+}
+
+func Test_Symo_GivenMutualTLSServer_WhenClientCertificateGiven_ThenAuthenticate(t *testing.T) {
+	cert, certFile, keyFile := newClientCertificate(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	server, caFile := newTLSServer(t, &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	})
+
+	c, err := NewSymoClient(ClientOptions{
+		URL: server.URL,
+		TLS: TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+	})
+	require.NoError(t, err)
+	p, err := c.GetPowerFlowData()
+	require.NoError(t, err)
+	assert.Equal(t, floatPtr(34.5), p.Inverters["1"].BatterySoC)
+
+	c, err = NewSymoClient(ClientOptions{
+		URL: server.URL,
+		TLS: TLSOptions{CAFile: caFile},
+	})
+	require.NoError(t, err)
+	_, err = c.GetPowerFlowData()
+	assert.Error(t, err)
+}
+
+func Test_NewSymoClient_GivenInvalidTLSOptions_ThenReturnError(t *testing.T) {
+	emptyFile := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(emptyFile, nil, 0o600))
+
+	tests := map[string]TLSOptions{
+		"GivenMissingCAFile_ThenReturnError":  {CAFile: "testdata/nonexistent.pem"},
+		"GivenEmptyCAFile_ThenReturnError":    {CAFile: emptyFile},
+		"GivenCertWithoutKey_ThenReturnError": {CertFile: emptyFile},
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSymoClient(ClientOptions{TLS: options})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package sunspec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	// readHoldingRegisters is the Modbus function code for reading holding registers.
+	readHoldingRegisters = 0x03
+	// maxRegistersPerRead is the highest number of registers that Modbus allows to read in a single request.
+	maxRegistersPerRead = 125
+	// mbapHeaderLength is the length of the Modbus Application Protocol header that precedes each PDU over TCP.
+	mbapHeaderLength = 7
+	// exceptionFlag is set in the function code of a response if the server returns an exception.
+	exceptionFlag = 0x80
+)
+
+// ExceptionError is returned if the Modbus server responds with an exception.
+type ExceptionError struct {
+	UnitID   uint8
+	Function uint8
+	Code     uint8
+}
+
+// Error implements error.
+func (e *ExceptionError) Error() string {
+	return fmt.Sprintf("modbus unit %d returned exception %d (%s) for function %d", e.UnitID, e.Code, exceptionName(e.Code), e.Function)
+}
+
+// exceptionName returns the name of the given Modbus exception code.
+func exceptionName(code uint8) string {
+	switch code {
+	case 1:
+		return "illegal function"
+	case 2:
+		return "illegal data address"
+	case 3:
+		return "illegal data value"
+	case 4:
+		return "server device failure"
+	case 6:
+		return "server device busy"
+	case 10:
+		return "gateway path unavailable"
+	case 11:
+		return "gateway target device failed to respond"
+	default:
+		return "unknown"
+	}
+}
+
+// readRegisters reads quantity holding registers starting at address from the given unit.
+// Reads of more than maxRegistersPerRead registers are split into multiple requests.
+func (c *Client) readRegisters(ctx context.Context, unitID uint8, address, quantity uint16) ([]uint16, error) {
+	registers := make([]uint16, 0, quantity)
+	for quantity > 0 {
+		n := min(quantity, maxRegistersPerRead)
+		chunk, err := c.readHoldingRegisters(ctx, unitID, address, n)
+		if err != nil {
+			return nil, err
+		}
+		registers = append(registers, chunk...)
+		address += n
+		quantity -= n
+	}
+	return registers, nil
+}
+
+// readHoldingRegisters sends a single read holding registers request to the given unit and returns the registers.
+// The connection is closed on any error other than a Modbus exception, so that the next request reconnects.
+// If the request failed because the given context is done, the error of the context is returned.
+func (c *Client) readHoldingRegisters(ctx context.Context, unitID uint8, address, quantity uint16) ([]uint16, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	registers, err := c.roundTrip(ctx, unitID, address, quantity)
+	if err != nil {
+		var exception *ExceptionError
+		if !errors.As(err, &exception) && c.conn != nil {
+			_ = c.conn.Close()
+			c.conn = nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	return registers, nil
+}
+
+func (c *Client) roundTrip(ctx context.Context, unitID uint8, address, quantity uint16) ([]uint16, error) {
+	if c.conn == nil {
+		dialer := net.Dialer{Timeout: c.Options.Timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", c.Options.Address)
+		if err != nil {
+			return nil, err
+		}
+		c.conn = conn
+	}
+	deadline := time.Now().Add(c.Options.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	// Reads and writes don't observe the context, so expire the deadline as soon as the context is done.
+	conn := c.conn
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	c.transactionID++
+	request := make([]byte, mbapHeaderLength+5)
+	binary.BigEndian.PutUint16(request[0:], c.transactionID)
+	binary.BigEndian.PutUint16(request[2:], 0) // protocol identifier of Modbus
+	binary.BigEndian.PutUint16(request[4:], 6) // length of the unit identifier and the PDU
+	request[6] = unitID
+	request[7] = readHoldingRegisters
+	binary.BigEndian.PutUint16(request[8:], address)
+	binary.BigEndian.PutUint16(request[10:], quantity)
+	if _, err := c.conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, mbapHeaderLength)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint16(header[4:])
+	if length < 2 || length > 256 {
+		return nil, fmt.Errorf("invalid modbus response length %d", length)
+	}
+	pdu := make([]byte, length-1)
+	if _, err := io.ReadFull(c.conn, pdu); err != nil {
+		return nil, err
+	}
+	if id := binary.BigEndian.Uint16(header[0:]); id != c.transactionID {
+		return nil, fmt.Errorf("modbus response has transaction id %d, expected %d", id, c.transactionID)
+	}
+
+	if pdu[0] == readHoldingRegisters|exceptionFlag {
+		return nil, &ExceptionError{UnitID: unitID, Function: readHoldingRegisters, Code: pdu[1]}
+	}
+	if pdu[0] != readHoldingRegisters {
+		return nil, fmt.Errorf("unexpected modbus function %d in response", pdu[0])
+	}
+	if int(pdu[1]) != 2*int(quantity) || len(pdu) != 2+2*int(quantity) {
+		return nil, fmt.Errorf("modbus response has %d bytes of data, expected %d", pdu[1], 2*quantity)
+	}
+	registers := make([]uint16, quantity)
+	for i := range registers {
+		registers[i] = binary.BigEndian.Uint16(pdu[2+2*i:])
+	}
+	return registers, nil
+}
@@ -0,0 +1,179 @@
+package sunspec
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modbusServer is an in-process Modbus TCP server that serves read holding registers requests from static register maps.
+type modbusServer struct {
+	listener net.Listener
+	// units holds the register map of each unit, starting at baseAddress.
+	units       map[uint8][]uint16
+	baseAddress uint16
+
+	mu       sync.Mutex
+	requests int
+}
+
+func newModbusServer(t *testing.T, baseAddress uint16, units map[uint8][]uint16) *modbusServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &modbusServer{listener: listener, units: units, baseAddress: baseAddress}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *modbusServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *modbusServer) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *modbusServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		request := make([]byte, 12)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.requests++
+		s.mu.Unlock()
+
+		unitID, function := request[6], request[7]
+		address, quantity := binary.BigEndian.Uint16(request[8:]), binary.BigEndian.Uint16(request[10:])
+		pdu := s.handle(unitID, function, address, quantity)
+
+		response := make([]byte, mbapHeaderLength, mbapHeaderLength+len(pdu))
+		copy(response, request[:4])
+		binary.BigEndian.PutUint16(response[4:], uint16(len(pdu)+1))
+		response[6] = unitID
+		if _, err := conn.Write(append(response, pdu...)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *modbusServer) handle(unitID, function uint8, address, quantity uint16) []byte {
+	registers, found := s.units[unitID]
+	switch {
+	case !found:
+		return []byte{function | exceptionFlag, 11}
+	case function != readHoldingRegisters:
+		return []byte{function | exceptionFlag, 1}
+	case quantity == 0 || quantity > maxRegistersPerRead:
+		return []byte{function | exceptionFlag, 3}
+	case address < s.baseAddress || int(address-s.baseAddress)+int(quantity) > len(registers):
+		return []byte{function | exceptionFlag, 2}
+	}
+	pdu := []byte{function, byte(2 * quantity)}
+	for _, register := range registers[address-s.baseAddress : address-s.baseAddress+quantity] {
+		pdu = binary.BigEndian.AppendUint16(pdu, register)
+	}
+	return pdu
+}
+
+func Test_Client_readRegisters_GivenMoreThanMaxRegisters_ThenSplitRequests(t *testing.T) {
+	registers := make([]uint16, 300)
+	for i := range registers {
+		registers[i] = uint16(i)
+	}
+	server := newModbusServer(t, 40000, map[uint8][]uint16{1: registers})
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	result, err := c.readRegisters(t.Context(), 1, 40000, 300)
+	require.NoError(t, err)
+	assert.Equal(t, registers, result)
+	assert.Equal(t, 3, server.Requests())
+}
+
+func Test_Client_readRegisters_GivenUnknownUnit_ThenReturnExceptionError(t *testing.T) {
+	server := newModbusServer(t, 40000, map[uint8][]uint16{1: {0}})
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.readRegisters(t.Context(), 240, 40000, 1)
+	var exception *ExceptionError
+	require.True(t, errors.As(err, &exception), err)
+	assert.Equal(t, uint8(11), exception.Code)
+	assert.EqualError(t, err, "modbus unit 240 returned exception 11 (gateway target device failed to respond) for function 3")
+
+	// An exception doesn't break the connection.
+	_, err = c.readRegisters(t.Context(), 1, 40000, 1)
+	assert.NoError(t, err)
+}
+
+func Test_Client_readRegisters_GivenServerNotResponding_ThenReturnTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			// Read the request, but never answer.
+			_, _ = io.Copy(io.Discard, conn)
+		}
+	}()
+
+	c, err := NewClient(ClientOptions{Address: listener.Addr().String(), Timeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+	defer c.Close()
+
+	start := time.Now()
+	_, err = c.readRegisters(t.Context(), 1, 40000, 1)
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr), err)
+	assert.True(t, netErr.Timeout())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func Test_Client_readRegisters_GivenServerNotResponding_WhenContextCancelled_ThenReturnContextError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			// Read the request, but never answer.
+			_, _ = io.Copy(io.Discard, conn)
+		}
+	}()
+
+	c, err := NewClient(ClientOptions{Address: listener.Addr().String(), Timeout: time.Minute})
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = c.readRegisters(ctx, 1, 40000, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,297 @@
+package sunspec
+
+import (
+	"math"
+	"strings"
+)
+
+type (
+	// Common holds the identification of a device from the common model.
+	Common struct {
+		Manufacturer string
+		Model        string
+		Options      string
+		Version      string
+		SerialNumber string
+		// DeviceAddress is the Modbus unit ID that the device reports for itself.
+		DeviceAddress uint16
+	}
+
+	// Inverter holds the values of an inverter model (101-103 or 111-113).
+	// Values that the device doesn't implement are nil.
+	Inverter struct {
+		// ModelID tells whether the inverter is single (101, 111), split (102, 112) or three phase (103, 113).
+		ModelID uint16
+		// Current is the total AC current in Ampere.
+		Current *float64
+		// PhaseCurrent holds the AC current of phase 1 to 3 in Ampere.
+		PhaseCurrent [3]*float64
+		// PhaseVoltage holds the AC voltage between phase 1 to 3 and neutral in Volt.
+		PhaseVoltage [3]*float64
+		// Power is the AC power in Watt.
+		Power *float64
+		// Frequency is the AC frequency in Hz.
+		Frequency *float64
+		// ApparentPower is the AC apparent power in VA.
+		ApparentPower *float64
+		// ReactivePower is the AC reactive power in VAr.
+		ReactivePower *float64
+		// PowerFactor is the power factor in percent.
+		PowerFactor *float64
+		// Energy is the AC energy generated overall in Wh.
+		Energy *float64
+		// DCCurrent, DCVoltage and DCPower are the DC values in Ampere, Volt and Watt.
+		DCCurrent *float64
+		DCVoltage *float64
+		DCPower   *float64
+		// CabinetTemperature is the temperature of the cabinet in degree Celsius.
+		CabinetTemperature *float64
+		// OperatingState is the SunSpec operating state, e.g. 4 if the inverter tracks the maximum power point.
+		OperatingState *float64
+	}
+
+	// MPPT holds the values of the multiple MPPT inverter extension model (160).
+	MPPT struct {
+		Modules []MPPTModule
+	}
+	// MPPTModule holds the DC values of a single maximum power point tracker of the inverter.
+	// Values that the device doesn't implement are nil.
+	MPPTModule struct {
+		ID    uint16
+		Label string
+		// Current, Voltage and Power are the DC values in Ampere, Volt and Watt.
+		Current *float64
+		Voltage *float64
+		Power   *float64
+		// Energy is the DC energy in Wh.
+		Energy *float64
+	}
+
+	// Meter holds the values of a meter model (201-204).
+	// Values that the device doesn't implement are nil.
+	Meter struct {
+		// ModelID tells whether the meter is single (201), split (202) or three phase (203, 204 for delta connection).
+		ModelID uint16
+		// Current is the total AC current in Ampere.
+		Current *float64
+		// PhaseCurrent holds the AC current of phase 1 to 3 in Ampere.
+		PhaseCurrent [3]*float64
+		// PhaseVoltage holds the AC voltage between phase 1 to 3 and neutral in Volt.
+		PhaseVoltage [3]*float64
+		// Frequency is the AC frequency in Hz.
+		Frequency *float64
+		// Power is the total active power in Watt. A positive value means that power is imported from the grid.
+		Power *float64
+		// PhasePower, PhaseApparentPower and PhaseReactivePower hold the power of phase 1 to 3 in Watt, VA and VAr.
+		PhasePower         [3]*float64
+		PhaseApparentPower [3]*float64
+		PhaseReactivePower [3]*float64
+		// PhasePowerFactor holds the power factor of phase 1 to 3 in percent.
+		PhasePowerFactor [3]*float64
+		// EnergyExported and EnergyImported are the active energy registers in Wh.
+		EnergyExported      *float64
+		EnergyImported      *float64
+		PhaseEnergyExported [3]*float64
+		PhaseEnergyImported [3]*float64
+		// ReactiveEnergyImported is the reactive energy of quadrant 1 and 2 in VArh,
+		// ReactiveEnergyExported the one of quadrant 3 and 4.
+		ReactiveEnergyImported *float64
+		ReactiveEnergyExported *float64
+	}
+)
+
+// decodeCommon decodes the registers of the common model (1).
+func decodeCommon(r []uint16) Common {
+	common := Common{
+		Manufacturer: decodeString(r, 0, 16),
+		Model:        decodeString(r, 16, 16),
+		Options:      decodeString(r, 32, 8),
+		Version:      decodeString(r, 40, 8),
+		SerialNumber: decodeString(r, 48, 16),
+	}
+	if len(r) > 64 {
+		common.DeviceAddress = r[64]
+	}
+	return common
+}
+
+// decodeInverter decodes the registers of an inverter model with either integer (101-103) or floating point values (111-113).
+func decodeInverter(id uint16, r []uint16) *Inverter {
+	if id >= 111 {
+		return &Inverter{
+			ModelID:            id,
+			Current:            decodeFloat32(r, 0),
+			PhaseCurrent:       [3]*float64{decodeFloat32(r, 2), decodeFloat32(r, 4), decodeFloat32(r, 6)},
+			PhaseVoltage:       [3]*float64{decodeFloat32(r, 14), decodeFloat32(r, 16), decodeFloat32(r, 18)},
+			Power:              decodeFloat32(r, 20),
+			Frequency:          decodeFloat32(r, 22),
+			ApparentPower:      decodeFloat32(r, 24),
+			ReactivePower:      decodeFloat32(r, 26),
+			PowerFactor:        decodeFloat32(r, 28),
+			Energy:             decodeFloat32(r, 30),
+			DCCurrent:          decodeFloat32(r, 32),
+			DCVoltage:          decodeFloat32(r, 34),
+			DCPower:            decodeFloat32(r, 36),
+			CabinetTemperature: decodeFloat32(r, 38),
+			OperatingState:     decodeEnum16(r, 46),
+		}
+	}
+	return &Inverter{
+		ModelID:            id,
+		Current:            decodeUint16(r, 0, 4),
+		PhaseCurrent:       [3]*float64{decodeUint16(r, 1, 4), decodeUint16(r, 2, 4), decodeUint16(r, 3, 4)},
+		PhaseVoltage:       [3]*float64{decodeUint16(r, 8, 11), decodeUint16(r, 9, 11), decodeUint16(r, 10, 11)},
+		Power:              decodeInt16(r, 12, 13),
+		Frequency:          decodeUint16(r, 14, 15),
+		ApparentPower:      decodeInt16(r, 16, 17),
+		ReactivePower:      decodeInt16(r, 18, 19),
+		PowerFactor:        decodeInt16(r, 20, 21),
+		Energy:             decodeAcc32(r, 22, 24),
+		DCCurrent:          decodeUint16(r, 25, 26),
+		DCVoltage:          decodeUint16(r, 27, 28),
+		DCPower:            decodeInt16(r, 29, 30),
+		CabinetTemperature: decodeInt16(r, 31, 35),
+		OperatingState:     decodeEnum16(r, 36),
+	}
+}
+
+const (
+	// mpptHeaderLength is the number of registers of the MPPT model before the first module.
+	mpptHeaderLength = 8
+	// mpptModuleLength is the number of registers of each module in the MPPT model.
+	mpptModuleLength = 20
+)
+
+// decodeMPPT decodes the registers of the multiple MPPT inverter extension model (160).
+// The scale factors in the header apply to all modules.
+func decodeMPPT(r []uint16) *MPPT {
+	mppt := &MPPT{}
+	if len(r) < mpptHeaderLength {
+		return mppt
+	}
+	count := int(r[6])
+	for i := 0; i < count; i++ {
+		offset := mpptHeaderLength + i*mpptModuleLength
+		if offset+mpptModuleLength > len(r) {
+			break
+		}
+		mppt.Modules = append(mppt.Modules, MPPTModule{
+			ID:      r[offset],
+			Label:   decodeString(r, offset+1, 8),
+			Current: decodeUint16(r, offset+9, 0),
+			Voltage: decodeUint16(r, offset+10, 1),
+			Power:   decodeUint16(r, offset+11, 2),
+			Energy:  decodeAcc32(r, offset+12, 3),
+		})
+	}
+	return mppt
+}
+
+// decodeMeter decodes the registers of a meter model with integer values (201-204).
+func decodeMeter(id uint16, r []uint16) *Meter {
+	return &Meter{
+		ModelID:            id,
+		Current:            decodeInt16(r, 0, 4),
+		PhaseCurrent:       [3]*float64{decodeInt16(r, 1, 4), decodeInt16(r, 2, 4), decodeInt16(r, 3, 4)},
+		PhaseVoltage:       [3]*float64{decodeInt16(r, 6, 13), decodeInt16(r, 7, 13), decodeInt16(r, 8, 13)},
+		Frequency:          decodeInt16(r, 14, 15),
+		Power:              decodeInt16(r, 16, 20),
+		PhasePower:         [3]*float64{decodeInt16(r, 17, 20), decodeInt16(r, 18, 20), decodeInt16(r, 19, 20)},
+		PhaseApparentPower: [3]*float64{decodeInt16(r, 22, 25), decodeInt16(r, 23, 25), decodeInt16(r, 24, 25)},
+		PhaseReactivePower: [3]*float64{decodeInt16(r, 27, 30), decodeInt16(r, 28, 30), decodeInt16(r, 29, 30)},
+		PhasePowerFactor:   [3]*float64{decodeInt16(r, 32, 35), decodeInt16(r, 33, 35), decodeInt16(r, 34, 35)},
+		EnergyExported:     decodeAcc32(r, 36, 52),
+		EnergyImported:     decodeAcc32(r, 44, 52),
+		PhaseEnergyExported: [3]*float64{
+			decodeAcc32(r, 38, 52), decodeAcc32(r, 40, 52), decodeAcc32(r, 42, 52),
+		},
+		PhaseEnergyImported: [3]*float64{
+			decodeAcc32(r, 46, 52), decodeAcc32(r, 48, 52), decodeAcc32(r, 50, 52),
+		},
+		ReactiveEnergyImported: sum(decodeAcc32(r, 70, 102), decodeAcc32(r, 78, 102)),
+		ReactiveEnergyExported: sum(decodeAcc32(r, 86, 102), decodeAcc32(r, 94, 102)),
+	}
+}
+
+// The following functions decode a value at the given register offset of a model.
+// They return nil if the model is too short for the value or if the value or its scale factor is not implemented,
+// which SunSpec signals with a reserved value per type.
+
+func decodeInt16(r []uint16, offset, sfOffset int) *float64 {
+	if offset >= len(r) || r[offset] == 0x8000 {
+		return nil
+	}
+	return scale(float64(int16(r[offset])), r, sfOffset)
+}
+
+func decodeUint16(r []uint16, offset, sfOffset int) *float64 {
+	if offset >= len(r) || r[offset] == 0xFFFF {
+		return nil
+	}
+	return scale(float64(r[offset]), r, sfOffset)
+}
+
+// decodeAcc32 decodes an accumulator, which is unsigned and not implemented if 0.
+func decodeAcc32(r []uint16, offset, sfOffset int) *float64 {
+	if offset+1 >= len(r) {
+		return nil
+	}
+	value := uint32(r[offset])<<16 | uint32(r[offset+1])
+	if value == 0 {
+		return nil
+	}
+	return scale(float64(value), r, sfOffset)
+}
+
+func decodeEnum16(r []uint16, offset int) *float64 {
+	if offset >= len(r) || r[offset] == 0xFFFF {
+		return nil
+	}
+	value := float64(r[offset])
+	return &value
+}
+
+// decodeFloat32 decodes an IEEE 754 value, which is not implemented if NaN.
+func decodeFloat32(r []uint16, offset int) *float64 {
+	if offset+1 >= len(r) {
+		return nil
+	}
+	value := float64(math.Float32frombits(uint32(r[offset])<<16 | uint32(r[offset+1])))
+	if math.IsNaN(value) {
+		return nil
+	}
+	return &value
+}
+
+// decodeString decodes a string of the given number of registers with two characters each, padded with NUL characters.
+func decodeString(r []uint16, offset, length int) string {
+	var b strings.Builder
+	for i := offset; i < offset+length && i < len(r); i++ {
+		b.WriteByte(byte(r[i] >> 8))
+		b.WriteByte(byte(r[i]))
+	}
+	return strings.TrimSpace(strings.TrimRight(b.String(), "\x00"))
+}
+
+// scale multiplies the value with the power of ten given by the scale factor at sfOffset.
+// Negative scale factors divide, since e.g. 0.1 has no exact floating point representation.
+func scale(value float64, r []uint16, sfOffset int) *float64 {
+	if sfOffset >= len(r) || r[sfOffset] == 0x8000 {
+		return nil
+	}
+	if sf := int(int16(r[sfOffset])); sf < 0 {
+		value /= math.Pow10(-sf)
+	} else {
+		value *= math.Pow10(sf)
+	}
+	return &value
+}
+
+// sum returns the sum of the given values, or nil if any of them is nil.
+func sum(a, b *float64) *float64 {
+	if a == nil || b == nil {
+		return nil
+	}
+	value := *a + *b
+	return &value
+}
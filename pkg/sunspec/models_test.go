@@ -0,0 +1,69 @@
+package sunspec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// floatPtr returns a pointer to the given value, for comparing optional values.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func Test_decodeValues(t *testing.T) {
+	tests := map[string]struct {
+		decode   func() *float64
+		expected *float64
+	}{
+		"GivenInt16WithNegativeScaleFactor_ThenDivide": {
+			decode:   func() *float64 { return decodeInt16([]uint16{2305, 0xFFFF}, 0, 1) },
+			expected: floatPtr(230.5),
+		},
+		"GivenNegativeInt16WithPositiveScaleFactor_ThenMultiply": {
+			decode:   func() *float64 { return decodeInt16([]uint16{0xFFFE, 2}, 0, 1) },
+			expected: floatPtr(-200),
+		},
+		"GivenInt16NotImplemented_ThenReturnNil": {
+			decode: func() *float64 { return decodeInt16([]uint16{0x8000, 0}, 0, 1) },
+		},
+		"GivenScaleFactorNotImplemented_ThenReturnNil": {
+			decode: func() *float64 { return decodeInt16([]uint16{1, 0x8000}, 0, 1) },
+		},
+		"GivenUint16Zero_ThenReturnZero": {
+			decode:   func() *float64 { return decodeUint16([]uint16{0, 0}, 0, 1) },
+			expected: floatPtr(0),
+		},
+		"GivenUint16NotImplemented_ThenReturnNil": {
+			decode: func() *float64 { return decodeUint16([]uint16{0xFFFF, 0}, 0, 1) },
+		},
+		"GivenAcc32_ThenCombineRegisters": {
+			decode:   func() *float64 { return decodeAcc32([]uint16{1, 2, 0}, 0, 2) },
+			expected: floatPtr(65538),
+		},
+		"GivenAcc32NotImplemented_ThenReturnNil": {
+			decode: func() *float64 { return decodeAcc32([]uint16{0, 0, 0}, 0, 2) },
+		},
+		"GivenOffsetOutOfModel_ThenReturnNil": {
+			decode: func() *float64 { return decodeUint16([]uint16{1}, 1, 0) },
+		},
+		"GivenFloat32_ThenDecode": {
+			decode:   func() *float64 { return decodeFloat32([]uint16{0x4366, 0x8000}, 0) },
+			expected: floatPtr(230.5),
+		},
+		"GivenFloat32NaN_ThenReturnNil": {
+			decode: func() *float64 { return decodeFloat32([]uint16{0x7FC0, 0}, 0) },
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.decode())
+		})
+	}
+}
+
+func Test_decodeString(t *testing.T) {
+	assert.Equal(t, "Fronius", decodeString(encodeString("Fronius", 16), 0, 16))
+	assert.Equal(t, "Fr", decodeString(encodeString("Fronius", 16), 0, 1))
+	assert.Equal(t, "", decodeString(nil, 0, 16))
+}
@@ -0,0 +1,216 @@
+package sunspec
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// CommonModel is the SunSpec model ID of the common block with the device identification.
+	CommonModel = 1
+	// MPPTModel is the SunSpec model ID of the multiple MPPT inverter extension.
+	MPPTModel = 160
+	// endModel is the model ID that marks the end of the SunSpec model list.
+	endModel = 0xFFFF
+)
+
+var (
+	// sunSpecMarker are the registers "SunS" that identify the start of the SunSpec register map.
+	sunSpecMarker = [2]uint16{0x5375, 0x6e53}
+	// baseAddresses are the register addresses where the SunSpec register map may start, in the order they are probed.
+	baseAddresses = []uint16{40000, 0, 50000}
+)
+
+type (
+	// Client reads SunSpec models of devices over Modbus TCP, e.g. from the Modbus interface of a Fronius Datamanager.
+	// It is safe for concurrent use by multiple goroutines, requests are sent one after the other over a single connection.
+	Client struct {
+		Options ClientOptions
+
+		mu            sync.Mutex
+		conn          net.Conn
+		transactionID uint16
+		// models caches the model list of each unit, since it doesn't change while the device is running.
+		models map[uint8][]modelHeader
+	}
+	// ClientOptions holds some parameters for the Client.
+	ClientOptions struct {
+		// Address is the host and port of the Modbus TCP server, e.g. symo.ip.or.hostname:502.
+		Address string
+		Timeout time.Duration
+		// UnitIDs are the Modbus unit IDs of the devices to read.
+		// Fronius inverters use 1 by default, the primary smart meter 240.
+		UnitIDs []uint8
+	}
+
+	// modelHeader is the location of a SunSpec model in the register map.
+	modelHeader struct {
+		ID uint16
+		// Address is the address of the first register after the header.
+		Address uint16
+		Length  uint16
+	}
+
+	// Device holds the SunSpec models read from a Modbus unit.
+	// Models that the device doesn't provide are nil.
+	Device struct {
+		UnitID   uint8
+		Common   Common
+		Inverter *Inverter
+		MPPT     *MPPT
+		Meter    *Meter
+	}
+)
+
+// NewClient returns a new Client with the given options.
+// The connection is established with the first request.
+func NewClient(options ClientOptions) (*Client, error) {
+	if options.Address == "" {
+		return nil, fmt.Errorf("no modbus address given")
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 5 * time.Second
+	}
+	return &Client{
+		Options: options,
+		models:  map[uint8][]modelHeader{},
+	}, nil
+}
+
+// Close closes the connection to the Modbus server, if any.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// GetDevice returns the supported SunSpec models of the device with the given unit ID.
+func (c *Client) GetDevice(unitID uint8) (*Device, error) {
+	return c.GetDeviceWithContext(context.Background(), unitID)
+}
+
+// GetDeviceWithContext is like GetDevice, but aborts the requests when the given context is done.
+func (c *Client) GetDeviceWithContext(ctx context.Context, unitID uint8) (*Device, error) {
+	models, err := c.getModels(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	device := &Device{UnitID: unitID}
+	for _, model := range models {
+		if !isSupportedModel(model.ID) {
+			continue
+		}
+		registers, err := c.readRegisters(ctx, unitID, model.Address, model.Length)
+		if err != nil {
+			// The model list may be outdated, e.g. after a firmware update.
+			c.forgetModels(unitID)
+			return nil, fmt.Errorf("cannot read model %d of unit %d: %w", model.ID, unitID, err)
+		}
+		switch {
+		case model.ID == CommonModel:
+			device.Common = decodeCommon(registers)
+		case isInverterModel(model.ID):
+			device.Inverter = decodeInverter(model.ID, registers)
+		case model.ID == MPPTModel:
+			device.MPPT = decodeMPPT(registers)
+		case isMeterModel(model.ID):
+			device.Meter = decodeMeter(model.ID, registers)
+		}
+	}
+	return device, nil
+}
+
+// getModels returns the model list of the given unit, discovering it on first use.
+func (c *Client) getModels(ctx context.Context, unitID uint8) ([]modelHeader, error) {
+	c.mu.Lock()
+	models, found := c.models[unitID]
+	c.mu.Unlock()
+	if found {
+		return models, nil
+	}
+
+	models, err := c.discoverModels(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.models[unitID] = models
+	c.mu.Unlock()
+	return models, nil
+}
+
+func (c *Client) forgetModels(unitID uint8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.models, unitID)
+}
+
+// discoverModels searches the SunSpec marker at the known base addresses and walks the model list that follows it.
+func (c *Client) discoverModels(ctx context.Context, unitID uint8) ([]modelHeader, error) {
+	address, err := c.findBaseAddress(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []modelHeader
+	for address += 2; ; {
+		header, err := c.readRegisters(ctx, unitID, address, 2)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read model header at %d of unit %d: %w", address, unitID, err)
+		}
+		if header[0] == endModel {
+			return models, nil
+		}
+		models = append(models, modelHeader{ID: header[0], Address: address + 2, Length: header[1]})
+		next := uint32(address) + 2 + uint32(header[1])
+		if next > 0xFFFF-2 {
+			return nil, fmt.Errorf("model list of unit %d exceeds the register range", unitID)
+		}
+		address = uint16(next)
+	}
+}
+
+func (c *Client) findBaseAddress(ctx context.Context, unitID uint8) (uint16, error) {
+	var lastErr error
+	for _, address := range baseAddresses {
+		marker, err := c.readRegisters(ctx, unitID, address, 2)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, err
+			}
+			lastErr = err
+			continue
+		}
+		if marker[0] == sunSpecMarker[0] && marker[1] == sunSpecMarker[1] {
+			return address, nil
+		}
+	}
+	if lastErr != nil {
+		return 0, fmt.Errorf("no sunspec register map found on unit %d: %w", unitID, lastErr)
+	}
+	return 0, fmt.Errorf("no sunspec register map found on unit %d", unitID)
+}
+
+func isSupportedModel(id uint16) bool {
+	return id == CommonModel || id == MPPTModel || isInverterModel(id) || isMeterModel(id)
+}
+
+// isInverterModel returns true for the single, split and three phase inverter models,
+// with integer and scale factors (101-103) or floating point values (111-113).
+func isInverterModel(id uint16) bool {
+	return (id >= 101 && id <= 103) || (id >= 111 && id <= 113)
+}
+
+// isMeterModel returns true for the single, split and three phase meter models with integer and scale factors.
+func isMeterModel(id uint16) bool {
+	return id >= 201 && id <= 204
+}
@@ -0,0 +1,296 @@
+package sunspec
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerMap builds a SunSpec register map from models.
+type registerMap []uint16
+
+func newRegisterMap() registerMap {
+	return registerMap{sunSpecMarker[0], sunSpecMarker[1]}
+}
+
+func (m registerMap) add(id uint16, data []uint16) registerMap {
+	m = append(m, id, uint16(len(data)))
+	return append(m, data...)
+}
+
+func (m registerMap) end() []uint16 {
+	return append(m, endModel, 0)
+}
+
+// encodeString encodes s into the given number of registers, padded with NUL characters.
+func encodeString(s string, length int) []uint16 {
+	b := make([]byte, 2*length)
+	copy(b, s)
+	registers := make([]uint16, length)
+	for i := range registers {
+		registers[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return registers
+}
+
+func commonModel(manufacturer, model, serial string, address uint16) []uint16 {
+	r := encodeString(manufacturer, 16)
+	r = append(r, encodeString(model, 16)...)
+	r = append(r, encodeString("", 8)...)
+	r = append(r, encodeString("1.2.3", 8)...)
+	r = append(r, encodeString(serial, 16)...)
+	return append(r, address, 0x8000)
+}
+
+// inverterModel103 returns a three phase inverter model with integer values and scale factors.
+func inverterModel103() []uint16 {
+	r := make([]uint16, 50)
+	copy(r, []uint16{
+		1170, 390, 380, 400, 0xFFFE, // A, AphA, AphB, AphC, A_SF=-2
+		0xFFFF, 0xFFFF, 0xFFFF, 2321, 2339, 2314, 0xFFFF, // PPVphAB-CA not implemented, PhVphA-C, V_SF=-1
+		2537, 0, // W, W_SF=0
+		5003, 0xFFFE, // Hz, Hz_SF=-2
+		2540, 0, // VA, VA_SF
+		0x8000, 0, // VAr not implemented
+		0xFC18, 0xFFFF, // PF=-1000, PF_SF=-1
+		0x0001, 0x5400, 0, // WH=87040, WH_SF
+		0xFFFF, 0xFFFF, // DCA not implemented
+		4458, 0xFFFF, // DCV, DCV_SF=-1
+		2600, 0, // DCW, DCW_SF
+		350, 0x8000, 0x8000, 0x8000, 0xFFFF, // TmpCab, TmpSnk, TmpTrns, TmpOt, Tmp_SF=-1
+		4, // St
+	})
+	return r
+}
+
+// inverterModel113 returns a three phase inverter model with floating point values.
+func inverterModel113() []uint16 {
+	r := make([]uint16, 60)
+	nan := math.Float32bits(float32(math.NaN()))
+	for i := 0; i < 46; i += 2 {
+		r[i], r[i+1] = uint16(nan>>16), uint16(nan)
+	}
+	set := func(offset int, value float32) {
+		bits := math.Float32bits(value)
+		r[offset], r[offset+1] = uint16(bits>>16), uint16(bits)
+	}
+	set(0, 11.5)
+	set(2, 3.75)
+	set(14, 231.5)
+	set(20, 2650)
+	set(22, 50)
+	set(30, 1392623)
+	r[46] = 4
+	return r
+}
+
+// mpptModel returns a MPPT model with the given number of modules, each with increasing values.
+func mpptModel(modules int) []uint16 {
+	r := []uint16{0xFFFE, 0xFFFF, 0, 0, 0, 0, uint16(modules), 0}
+	for i := 1; i <= modules; i++ {
+		module := []uint16{uint16(i)}
+		module = append(module, encodeString("String "+string(rune('0'+i)), 8)...)
+		module = append(module, uint16(100*i), uint16(4000+i), uint16(400*i), 0, uint16(1000*i), 0, 0, 0x8000, 4, 0, 0)
+		r = append(r, module...)
+	}
+	return r
+}
+
+// meterModel203 returns a three phase meter model with integer values and scale factors.
+func meterModel203() []uint16 {
+	r := make([]uint16, 105)
+	copy(r, []uint16{
+		1170, 390, 380, 400, 0xFFFE, // A, AphA-C, A_SF=-2
+		2325, 2321, 2339, 2314, 4027, 4034, 4027, 4001, 0xFFFF, // PhV, PhVphA-C, PPV, PPVphAB-CA, V_SF=-1
+		5003, 0xFFFE, // Hz, Hz_SF=-2
+		0xFE0C, 0xFF38, 0xFF38, 0xFF9C, 0, // W=-500, WphA=-200, WphB=-200, WphC=-100, W_SF
+		600, 250, 250, 100, 0, // VA, VAphA-C, VA_SF
+		300, 100, 100, 100, 0, // VAR, VARphA-C, VAR_SF
+		0xFC18, 0xFC18, 0xFC7C, 0xFCE0, 0xFFFF, // PF, PFphA-C, PF_SF=-1
+	})
+	copy(r[36:], []uint16{
+		0, 3000, 0, 1000, 0, 1000, 0, 1000, // TotWhExp, TotWhExpPhA-C
+		0, 6000, 0, 2000, 0, 2000, 0, 2000, // TotWhImp, TotWhImpPhA-C
+		1, // TotWh_SF
+	})
+	copy(r[70:], []uint16{0, 10, 0, 0, 0, 0, 0, 0, 0, 20}) // TotVArhImpQ1, TotVArhImpQ2 at 78
+	copy(r[86:], []uint16{0, 30, 0, 0, 0, 0, 0, 0, 0, 40}) // TotVArhExpQ3, TotVArhExpQ4 at 94
+	r[102] = 0                                             // TotVArh_SF
+	return r
+}
+
+func newFroniusServer(t *testing.T, baseAddress uint16) *modbusServer {
+	inverter := newRegisterMap().
+		add(CommonModel, commonModel("Fronius", "Symo 8.2-3-M", "12345678", 1)).
+		add(103, inverterModel103()).
+		add(120, make([]uint16, 26)).
+		add(MPPTModel, mpptModel(2)).
+		end()
+	meter := newRegisterMap().
+		add(CommonModel, commonModel("Fronius", "Smart Meter 63A", "87654321", 240)).
+		add(203, meterModel203()).
+		end()
+	return newModbusServer(t, baseAddress, map[uint8][]uint16{1: inverter, 240: meter})
+}
+
+func Test_Client_GetDevice_GivenInverter_ThenDecodeModels(t *testing.T) {
+	server := newFroniusServer(t, 40000)
+	c, err := NewClient(ClientOptions{Address: server.Addr(), UnitIDs: []uint8{1, 240}})
+	require.NoError(t, err)
+	defer c.Close()
+
+	device, err := c.GetDevice(1)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), device.UnitID)
+	assert.Equal(t, Common{
+		Manufacturer:  "Fronius",
+		Model:         "Symo 8.2-3-M",
+		Version:       "1.2.3",
+		SerialNumber:  "12345678",
+		DeviceAddress: 1,
+	}, device.Common)
+	assert.Nil(t, device.Meter)
+
+	inverter := device.Inverter
+	require.NotNil(t, inverter)
+	assert.Equal(t, uint16(103), inverter.ModelID)
+	assert.Equal(t, floatPtr(11.7), inverter.Current)
+	assert.Equal(t, [3]*float64{floatPtr(3.9), floatPtr(3.8), floatPtr(4)}, inverter.PhaseCurrent)
+	assert.Equal(t, [3]*float64{floatPtr(232.1), floatPtr(233.9), floatPtr(231.4)}, inverter.PhaseVoltage)
+	assert.Equal(t, floatPtr(2537), inverter.Power)
+	assert.Equal(t, floatPtr(50.03), inverter.Frequency)
+	assert.Nil(t, inverter.ReactivePower)
+	assert.Equal(t, floatPtr(-100), inverter.PowerFactor)
+	assert.Equal(t, floatPtr(87040), inverter.Energy)
+	assert.Nil(t, inverter.DCCurrent)
+	assert.Equal(t, floatPtr(445.8), inverter.DCVoltage)
+	assert.Equal(t, floatPtr(35), inverter.CabinetTemperature)
+	assert.Equal(t, floatPtr(4), inverter.OperatingState)
+
+	require.NotNil(t, device.MPPT)
+	assert.Equal(t, []MPPTModule{
+		{ID: 1, Label: "String 1", Current: floatPtr(1), Voltage: floatPtr(400.1), Power: floatPtr(400), Energy: floatPtr(1000)},
+		{ID: 2, Label: "String 2", Current: floatPtr(2), Voltage: floatPtr(400.2), Power: floatPtr(800), Energy: floatPtr(2000)},
+	}, device.MPPT.Modules)
+}
+
+func Test_Client_GetDevice_GivenMeter_ThenDecodeModel(t *testing.T) {
+	server := newFroniusServer(t, 40000)
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	device, err := c.GetDevice(240)
+	require.NoError(t, err)
+	assert.Equal(t, "Smart Meter 63A", device.Common.Model)
+	assert.Nil(t, device.Inverter)
+	assert.Nil(t, device.MPPT)
+
+	meter := device.Meter
+	require.NotNil(t, meter)
+	assert.Equal(t, uint16(203), meter.ModelID)
+	assert.Equal(t, [3]*float64{floatPtr(232.1), floatPtr(233.9), floatPtr(231.4)}, meter.PhaseVoltage)
+	assert.Equal(t, floatPtr(50.03), meter.Frequency)
+	assert.Equal(t, floatPtr(-500), meter.Power)
+	assert.Equal(t, [3]*float64{floatPtr(-200), floatPtr(-200), floatPtr(-100)}, meter.PhasePower)
+	assert.Equal(t, [3]*float64{floatPtr(-100), floatPtr(-90), floatPtr(-80)}, meter.PhasePowerFactor)
+	assert.Equal(t, floatPtr(30000), meter.EnergyExported)
+	assert.Equal(t, floatPtr(60000), meter.EnergyImported)
+	assert.Equal(t, [3]*float64{floatPtr(20000), floatPtr(20000), floatPtr(20000)}, meter.PhaseEnergyImported)
+	assert.Equal(t, floatPtr(30), meter.ReactiveEnergyImported)
+	assert.Equal(t, floatPtr(70), meter.ReactiveEnergyExported)
+}
+
+func Test_Client_GetDevice_GivenFloatInverter_ThenDecodeModel(t *testing.T) {
+	registers := newRegisterMap().
+		add(CommonModel, commonModel("Fronius", "Primo GEN24 6.0", "1", 1)).
+		add(113, inverterModel113()).
+		end()
+	server := newModbusServer(t, 40000, map[uint8][]uint16{1: registers})
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	device, err := c.GetDevice(1)
+	require.NoError(t, err)
+	inverter := device.Inverter
+	require.NotNil(t, inverter)
+	assert.Equal(t, uint16(113), inverter.ModelID)
+	assert.Equal(t, floatPtr(11.5), inverter.Current)
+	assert.Equal(t, [3]*float64{floatPtr(3.75), nil, nil}, inverter.PhaseCurrent)
+	assert.Equal(t, floatPtr(231.5), inverter.PhaseVoltage[0])
+	assert.Equal(t, floatPtr(2650), inverter.Power)
+	assert.Equal(t, floatPtr(50), inverter.Frequency)
+	assert.Equal(t, floatPtr(1392623), inverter.Energy)
+	assert.Nil(t, inverter.DCVoltage)
+	assert.Equal(t, floatPtr(4), inverter.OperatingState)
+}
+
+func Test_Client_GetDevice_GivenBaseAddressZero_ThenDiscoverModels(t *testing.T) {
+	server := newFroniusServer(t, 0)
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	device, err := c.GetDevice(1)
+	require.NoError(t, err)
+	assert.NotNil(t, device.Inverter)
+}
+
+func Test_Client_GetDevice_GivenLargeModel_ThenSplitRequests(t *testing.T) {
+	registers := newRegisterMap().add(MPPTModel, mpptModel(8)).end()
+	server := newModbusServer(t, 40000, map[uint8][]uint16{1: registers})
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	device, err := c.GetDevice(1)
+	require.NoError(t, err)
+	require.Len(t, device.MPPT.Modules, 8)
+	assert.Equal(t, floatPtr(3200), device.MPPT.Modules[7].Power)
+}
+
+func Test_Client_GetDevice_WhenCalledTwice_ThenReuseModelList(t *testing.T) {
+	server := newFroniusServer(t, 40000)
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.GetDevice(240)
+	require.NoError(t, err)
+	discovery := server.Requests()
+
+	_, err = c.GetDevice(240)
+	require.NoError(t, err)
+	// Only the common and the meter model are read again.
+	assert.Equal(t, 2, server.Requests()-discovery)
+}
+
+func Test_Client_GetDevice_GivenUnknownUnit_ThenReturnError(t *testing.T) {
+	server := newFroniusServer(t, 40000)
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.GetDevice(2)
+	var exception *ExceptionError
+	assert.True(t, errors.As(err, &exception), err)
+}
+
+func Test_Client_GetDevice_GivenNoSunSpecMarker_ThenReturnError(t *testing.T) {
+	server := newModbusServer(t, 40000, map[uint8][]uint16{1: {0, 0, 0, 0}})
+	c, err := NewClient(ClientOptions{Address: server.Addr()})
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.GetDevice(1)
+	assert.ErrorContains(t, err, "no sunspec register map found on unit 1")
+}
+
+func Test_NewClient_GivenNoAddress_ThenReturnError(t *testing.T) {
+	_, err := NewClient(ClientOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ccremer/fronius-exporter/pkg/fronius"
+	"github.com/ccremer/fronius-exporter/pkg/sunspec"
+	log "github.com/sirupsen/logrus"
+)
+
+// unknownMeterLocation is the location label of meters read over Modbus,
+// since the SunSpec meter models don't contain the installation point.
+const unknownMeterLocation = "unknown"
+
+// collectSunSpecMetrics reads the configured units over Modbus and updates the same metrics that the Solar API endpoints do.
+// Units are read one after the other, since they share a single connection.
+func collectSunSpecMetrics(ctx context.Context, client *sunspec.Client) {
+	start := time.Now()
+	log.WithFields(log.Fields{
+		"address": client.Options.Address,
+		"timeout": client.Options.Timeout,
+		"unitIDs": client.Options.UnitIDs,
+	}).Debug("Requesting data.")
+
+	deviceInfo := fronius.ActiveDeviceInfo{
+		Inverters: map[string]fronius.ActiveDevice{},
+		Meters:    map[string]fronius.ActiveDevice{},
+	}
+	resetInverterRealtimeMetrics()
+	resetMeterMetrics()
+	for _, unitID := range client.Options.UnitIDs {
+		device, err := client.GetDeviceWithContext(ctx, unitID)
+		if err != nil {
+			handleScrapeError(ctx, "modbus", err, log.Fields{"unit": unitID}, "Could not collect SunSpec metrics.")
+			continue
+		}
+		parseSunSpecDevice(device)
+
+		id := strconv.Itoa(int(device.UnitID))
+		// SunSpec has no Fronius device types, hence -1 like the Solar API reports for meters.
+		info := fronius.ActiveDevice{DT: -1, Serial: device.Common.SerialNumber}
+		if device.Inverter != nil {
+			deviceInfo.Inverters[id] = info
+		}
+		if device.Meter != nil {
+			deviceInfo.Meters[id] = info
+		}
+	}
+	parseDeviceInfo(&deviceInfo)
+
+	elapsed := time.Since(start)
+	scrapeDurationGauge.Set(elapsed.Seconds())
+}
+
+// parseSunSpecDevice converts the models of the device to the data of the Solar API and parses it.
+// The unit ID is used as inverter and meter ID.
+func parseSunSpecDevice(device *sunspec.Device) {
+	log.WithField("device", *device).Debug("Parsing data.")
+	id := strconv.Itoa(int(device.UnitID))
+
+	if inverter := device.Inverter; inverter != nil {
+		setOptional(inverterPowerGaugeVec, inverter.Power, id)
+
+		realtimeData := fronius.SymoInverterRealtimeData{
+			DcCurrentMPPT1:       dataPoint("A", inverter.DCCurrent),
+			DcVoltageMPPT1:       dataPoint("V", inverter.DCVoltage),
+			AcFrequency:          dataPoint("Hz", inverter.Frequency),
+			AcPower:              dataPoint("W", inverter.Power),
+			TotalEnergyGenerated: dataPoint("Wh", inverter.Energy),
+		}
+		if device.MPPT != nil {
+			// The MPPT model replaces the DC values of the inverter model, which are the sum of all trackers.
+			currents := []*fronius.RealTimeDataPoint{&realtimeData.DcCurrentMPPT1, &realtimeData.DcCurrentMPPT2, &realtimeData.DcCurrentMPPT3, &realtimeData.DcCurrentMPPT4}
+			voltages := []*fronius.RealTimeDataPoint{&realtimeData.DcVoltageMPPT1, &realtimeData.DcVoltageMPPT2, &realtimeData.DcVoltageMPPT3, &realtimeData.DcVoltageMPPT4}
+			for i := range currents {
+				var module sunspec.MPPTModule
+				if i < len(device.MPPT.Modules) {
+					module = device.MPPT.Modules[i]
+				}
+				*currents[i] = dataPoint("A", module.Current)
+				*voltages[i] = dataPoint("V", module.Voltage)
+			}
+		}
+		parseInverterRealtimeData(id, &realtimeData)
+
+		parseInverter3PData(id, &fronius.SymoInverter3PData{
+			AcCurrentL1: dataPoint("A", inverter.PhaseCurrent[0]),
+			AcCurrentL2: dataPoint("A", inverter.PhaseCurrent[1]),
+			AcCurrentL3: dataPoint("A", inverter.PhaseCurrent[2]),
+			AcVoltageL1: dataPoint("V", inverter.PhaseVoltage[0]),
+			AcVoltageL2: dataPoint("V", inverter.PhaseVoltage[1]),
+			AcVoltageL3: dataPoint("V", inverter.PhaseVoltage[2]),
+		})
+	}
+
+	if device.Meter != nil {
+		parseSunSpecMeter(id, device.Meter)
+	}
+}
+
+// parseSunSpecMeter sets the meter metrics like parseMeterRealtimeData, but deletes the values that the meter doesn't implement.
+func parseSunSpecMeter(meterID string, meter *sunspec.Meter) {
+	log.WithFields(log.Fields{
+		"meter": meterID,
+		"Meter": *meter,
+	}).Debug("Parsing data.")
+	setOptional(siteMeterRealTimeDataEnergyReal_WAC_Sum_Consumed, meter.EnergyImported, meterID, unknownMeterLocation)
+	setOptional(siteMeterRealTimeDataEnergyReal_WAC_Sum_Produced, meter.EnergyExported, meterID, unknownMeterLocation)
+	for i, phase := range []string{"1", "2", "3"} {
+		setOptional(siteMeterVoltageGaugeVec, meter.PhaseVoltage[i], meterID, unknownMeterLocation, phase)
+		setOptional(siteMeterCurrentGaugeVec, meter.PhaseCurrent[i], meterID, unknownMeterLocation, phase)
+		setOptional(siteMeterPowerRealGaugeVec, meter.PhasePower[i], meterID, unknownMeterLocation, phase)
+		setOptional(siteMeterPowerReactiveGaugeVec, meter.PhaseReactivePower[i], meterID, unknownMeterLocation, phase)
+		setOptional(siteMeterPowerApparentGaugeVec, meter.PhaseApparentPower[i], meterID, unknownMeterLocation, phase)
+		setOptional(siteMeterPowerFactorGaugeVec, percentToRatio(meter.PhasePowerFactor[i]), meterID, unknownMeterLocation, phase)
+	}
+	setOptional(siteMeterFrequencyGaugeVec, meter.Frequency, meterID, unknownMeterLocation)
+
+	siteMeterEnergyRegisters.update(meterID, meterEnergy{
+		location:         unknownMeterLocation,
+		realConsumed:     meter.PhaseEnergyImported,
+		realProduced:     meter.PhaseEnergyExported,
+		reactiveConsumed: meter.ReactiveEnergyImported,
+		reactiveProduced: meter.ReactiveEnergyExported,
+	})
+}
+
+func dataPoint(unit string, value *float64) fronius.RealTimeDataPoint {
+	return fronius.RealTimeDataPoint{Unit: unit, Value: value}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ccremer/fronius-exporter/pkg/sunspec"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// floatPtr returns a pointer to the given value, for optional values.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func Test_parseSunSpecMeter_GivenNotImplementedValues_ThenDeleteMetrics(t *testing.T) {
+	meter := &sunspec.Meter{
+		ModelID:             203,
+		PhaseVoltage:        [3]*float64{floatPtr(232.1), floatPtr(233.9), floatPtr(231.4)},
+		PhasePowerFactor:    [3]*float64{floatPtr(-100), nil, nil},
+		EnergyImported:      floatPtr(60000),
+		PhaseEnergyImported: [3]*float64{floatPtr(20000), nil, nil},
+	}
+	resetMeterMetrics()
+	parseSunSpecMeter("240", meter)
+
+	assert.Equal(t, 232.1, testutil.ToFloat64(siteMeterVoltageGaugeVec.WithLabelValues("240", unknownMeterLocation, "1")))
+	assert.Equal(t, -1.0, testutil.ToFloat64(siteMeterPowerFactorGaugeVec.WithLabelValues("240", unknownMeterLocation, "1")))
+	assert.Equal(t, 60000.0, testutil.ToFloat64(siteMeterRealTimeDataEnergyReal_WAC_Sum_Consumed.WithLabelValues("240", unknownMeterLocation)))
+	assert.False(t, siteMeterFrequencyGaugeVec.DeleteLabelValues("240", unknownMeterLocation), "frequency should not be exported")
+	assert.False(t, siteMeterRealTimeDataEnergyReal_WAC_Sum_Produced.DeleteLabelValues("240", unknownMeterLocation), "energy produced should not be exported")
+	assert.False(t, siteMeterCurrentGaugeVec.DeleteLabelValues("240", unknownMeterLocation, "1"), "current should not be exported")
+	assert.Equal(t, 1, testutil.CollectAndCount(siteMeterEnergyRegisters), "only the implemented energy register should be exported")
+}